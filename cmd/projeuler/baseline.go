@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// baselineDir keeps baselines of bench, a file for each, in stateDir.
+const baselineDir = "baselines"
+
+var baselineNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Baseline is statistics of methods saved by bench, to compare later runs with.
+type Baseline struct {
+	Name        string         `json:"name"`
+	SavedAt     time.Time      `json:"saved_at"`
+	Environment Environment    `json:"environment"`
+	Results     []*BenchResult `json:"results"`
+}
+
+func baselinePath(name string) (string, error) {
+	if !baselineNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid baseline name '%s', only letters, digits, '.', '_' and '-' allowed", name)
+	}
+
+	return filepath.Join(stateDir, baselineDir, name+".json"), nil
+}
+
+// LoadBaseline reads baseline saved in name.
+func LoadBaseline(name string) (*Baseline, error) {
+	path, err := baselinePath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no baseline '%s', save one with 'projeuler bench -save %s'", name, name)
+
+	} else if err != nil {
+		return nil, err
+	}
+
+	baseline := &Baseline{}
+	if err := json.Unmarshal(data, baseline); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return baseline, nil
+}
+
+// Save writes baseline, replacing one of the same name.
+func (b *Baseline) Save() error {
+	path, err := baselinePath(b.Name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Find returns result of method in baseline, nil if it is not found.
+func (b *Baseline) Find(problemId int, method string) *BenchResult {
+	for _, result := range b.Results {
+		if result.ProblemId == problemId && result.Method == method {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// Comparison is the difference of a method between baseline and current run.
+type Comparison struct {
+	Baseline *BenchResult
+	Current  *BenchResult
+	// Delta is change of median time cost, relative to baseline.
+	Delta float64
+	// P is p-value of Mann-Whitney U test, the chance that a difference as large is seen if the
+	// method is not changed.
+	P float64
+}
+
+func samplesToFloat(samples []time.Duration) []float64 {
+	result := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		result = append(result, float64(sample))
+	}
+
+	return result
+}
+
+func Compare(baseline, current *BenchResult) Comparison {
+	c := Comparison{
+		Baseline: baseline,
+		Current:  current,
+		P:        1,
+	}
+
+	if baseline == nil || len(baseline.Samples) == 0 || len(current.Samples) == 0 {
+		return c
+	}
+
+	c.Delta = float64(current.Median())/float64(baseline.Median()) - 1
+	_, c.P = framework.MannWhitneyU(samplesToFloat(baseline.Samples), samplesToFloat(current.Samples))
+	return c
+}
+
+// Significant tells whether difference is unlikely by chance, at significance level alpha.
+func (c Comparison) Significant(alpha float64) bool {
+	return c.P < alpha
+}
+
+// Regression tells whether method is significantly slower, by more than threshold.
+func (c Comparison) Regression(alpha float64, threshold float64) bool {
+	return c.Significant(alpha) && c.Delta > threshold
+}
+
+func benchCompareFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.StringVar(&conf.BenchSave, "save", "", "save statistics of methods as baseline `name`")
+	fs.StringVar(&conf.BenchCompare, "compare", "", "compare methods with baseline `name`")
+	fs.Float64Var(&conf.BenchAlpha, "alpha", 0.05,
+		"significance level of comparison, differences with p-value under it are significant")
+	fs.Float64Var(&conf.BenchThreshold, "threshold", 10,
+		"slowdown in percent, over which a significant one is a regression and fails bench")
+}
+
+// compareBaseline prints comparison of results with baseline, and returns number of regressions.
+func compareBaseline(conf *framework.Configure, baseline *Baseline, results []*BenchResult) int {
+	fmt.Printf("\ncompare with baseline '%s', saved at %s on commit %s\n", baseline.Name,
+		baseline.SavedAt.Local().Format("2006-01-02 15:04:05"), baseline.Environment.CommitLabel())
+	fmt.Printf("%-46s %12s %12s %9s %7s\n", "", "baseline", "current", "delta", "p")
+
+	regressions := 0
+	lastProblem := 0
+	for _, current := range results {
+		if current.ProblemId != lastProblem {
+			problem, _ := problems.GetProblem(current.ProblemId)
+			fmt.Printf("%-5d %s\n", problem.Id, problem.Title)
+			lastProblem = current.ProblemId
+		}
+
+		c := Compare(baseline.Find(current.ProblemId, current.Method), current)
+		if c.Regression(conf.BenchAlpha, conf.BenchThreshold/100) {
+			regressions++
+		}
+
+		printComparison(conf, c)
+	}
+
+	if regressions > 0 {
+		fmt.Printf("%d regressions over %g%%\n", regressions, conf.BenchThreshold)
+	}
+
+	return regressions
+}
+
+func printComparison(conf *framework.Configure, c Comparison) {
+	name := rightPadding(c.Current.Method, 38, ".")
+	_, current := toMsString(c.Current.Median())
+	if c.Baseline == nil {
+		fmt.Printf("      + %-38s %12s %s %9s %7s  not in baseline\n", name, "", current, "", "")
+		return
+	}
+
+	if len(c.Baseline.Samples) == 0 || len(c.Current.Samples) == 0 {
+		fmt.Printf("      + %-38s %12s %s %9s %7s  no samples to compare\n", name, "", current, "", "")
+		return
+	}
+
+	_, baseline := toMsString(c.Baseline.Median())
+	verdict := "~"
+	switch {
+	case c.Regression(conf.BenchAlpha, conf.BenchThreshold/100):
+		verdict = color.RedString("regression")
+
+	case c.Significant(conf.BenchAlpha) && c.Delta > 0:
+		verdict = color.YellowString("slower")
+
+	case c.Significant(conf.BenchAlpha):
+		verdict = color.GreenString("faster")
+	}
+
+	if c.Significant(conf.BenchAlpha) {
+		verdict += fmt.Sprintf(" (%.1f%% confidence)", (1-c.P)*100)
+	}
+
+	fmt.Printf("      + %-38s %s %s %+8.1f%% %7.3f  %s\n", name, baseline, current,
+		c.Delta*100, c.P, verdict)
+}
+
+// checkBaselineFlags validates flags of baseline before running bench.
+func checkBaselineFlags(conf *framework.Configure) error {
+	if conf.BenchSave != "" {
+		if _, err := baselinePath(conf.BenchSave); err != nil {
+			return err
+		}
+	}
+
+	if conf.BenchAlpha <= 0 || conf.BenchAlpha >= 1 || math.IsNaN(conf.BenchAlpha) {
+		return fmt.Errorf("invalid alpha %g, it must be between 0 and 1", conf.BenchAlpha)
+	}
+
+	if conf.BenchThreshold < 0 {
+		return fmt.Errorf("invalid threshold %g%%, it must not be negative", conf.BenchThreshold)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func makeBenchResult(ms ...int) *BenchResult {
+	result := &BenchResult{ProblemId: 14, Method: "naive"}
+	for _, v := range ms {
+		result.Samples = append(result.Samples, time.Duration(v)*time.Millisecond)
+	}
+
+	return result
+}
+
+func TestComparisonRegression(t *testing.T) {
+	const alpha, threshold = 0.05, 0.1
+	cases := []struct {
+		name        string
+		baseline    *BenchResult
+		current     *BenchResult
+		significant bool
+		regression  bool
+	}{
+		{"significant over threshold", makeBenchResult(100, 101, 102, 103, 104),
+			makeBenchResult(200, 201, 202, 203, 204), true, true},
+		{"significant under threshold", makeBenchResult(100, 101, 102, 103, 104),
+			makeBenchResult(105, 106, 107, 108, 109), true, false},
+		{"over threshold not significant", makeBenchResult(100, 200, 300),
+			makeBenchResult(50, 250, 400), false, false},
+		{"significantly faster", makeBenchResult(200, 201, 202, 203, 204),
+			makeBenchResult(100, 101, 102, 103, 104), true, false},
+		{"missing baseline", nil, makeBenchResult(200, 201, 202, 203, 204), false, false},
+		{"baseline without samples", makeBenchResult(), makeBenchResult(200, 201, 202, 203, 204), false, false},
+	}
+
+	for _, c := range cases {
+		comparison := Compare(c.baseline, c.current)
+		if got := comparison.Significant(alpha); got != c.significant {
+			t.Errorf("%s: significant is %v, expected %v, delta %.3f p %.4f", c.name, got, c.significant,
+				comparison.Delta, comparison.P)
+		}
+
+		if got := comparison.Regression(alpha, threshold); got != c.regression {
+			t.Errorf("%s: regression is %v, expected %v, delta %.3f p %.4f", c.name, got, c.regression,
+				comparison.Delta, comparison.P)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// BenchResult is time costs of all runs of a method. Runs stop at the first failed one, whose
+// status is kept in Status.
+type BenchResult struct {
+	ProblemId int                    `json:"problem"`
+	Method    string                 `json:"method"`
+	Samples   []time.Duration        `json:"samples_ns"`
+	Status    framework.ResultStatus `json:"status"`
+	// Budget is timeout of the method, time cost is coloured relative to it.
+	Budget time.Duration `json:"-"`
+}
+
+func (r *BenchResult) sorted() []time.Duration {
+	samples := make([]time.Duration, len(r.Samples))
+	copy(samples, r.Samples)
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples
+}
+
+func (r *BenchResult) Min() time.Duration {
+	if len(r.Samples) == 0 {
+		return 0
+	}
+
+	return r.sorted()[0]
+}
+
+func (r *BenchResult) Max() time.Duration {
+	if len(r.Samples) == 0 {
+		return 0
+	}
+
+	return r.sorted()[len(r.Samples)-1]
+}
+
+func (r *BenchResult) Median() time.Duration {
+	samples := r.sorted()
+	n := len(samples)
+	switch {
+	case n == 0:
+		return 0
+
+	case n%2 == 1:
+		return samples[n/2]
+
+	default:
+		return (samples[n/2-1] + samples[n/2]) / 2
+	}
+}
+
+func (r *BenchResult) Mean() time.Duration {
+	if len(r.Samples) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, sample := range r.Samples {
+		total += sample
+	}
+
+	return total / time.Duration(len(r.Samples))
+}
+
+// doBench runs each method several times and prints statistics. Statistics are compared with
+// baseline in -compare, and saved as baseline in -save.
+func doBench(conf *framework.Configure) {
+	if conf.BenchCount < 1 {
+		conf.BenchCount = 1
+	}
+
+	if err := checkBaselineFlags(conf); err != nil {
+		os.Exit(errorExitCode(&UsageError{err}))
+	}
+
+	var baseline *Baseline
+	if conf.BenchCompare != "" {
+		var err error
+		if baseline, err = LoadBaseline(conf.BenchCompare); err != nil {
+			os.Exit(errorExitCode(&UsageError{err}))
+		}
+	}
+
+	results, err := runBench(conf)
+	if err != nil {
+		os.Exit(errorExitCode(err))
+	}
+
+	regressions := 0
+	if baseline != nil {
+		regressions = compareBaseline(conf, baseline, results)
+	}
+
+	if conf.BenchSave != "" {
+		saved := &Baseline{
+			Name:        conf.BenchSave,
+			SavedAt:     time.Now(),
+			Environment: CurrentEnvironment(),
+			Results:     results,
+		}
+
+		if err := saved.Save(); err != nil {
+			os.Exit(errorExitCode(fmt.Errorf("save baseline failed: %w", err)))
+		}
+
+		fmt.Printf("saved %d methods as baseline '%s'\n", len(results), saved.Name)
+	}
+
+	if regressions > 0 {
+		os.Exit(ExitRegression)
+	}
+}
+
+// runBench runs each selected method several times, and returns statistics of them.
+func runBench(conf *framework.Configure) ([]*BenchResult, error) {
+	selected, err := selectProblems(conf, problems.Problems)
+	if err != nil {
+		return nil, &UsageError{err}
+	}
+
+	pool, err := NewWorkerPool(conf, conf.SpareWorkers)
+	if err != nil {
+		return nil, fmt.Errorf("start worker failed: %w", err)
+	}
+
+	isolate := IsolateStats{}
+	defer func() {
+		pool.Close()
+		printPoolStats(pool.Stats())
+		if conf.Isolate {
+			printIsolateStats(isolate)
+		}
+	}()
+
+	results := make([]*BenchResult, 0)
+	fmt.Printf("%-46s %12s %12s %12s %12s\n", "", "min", "median", "mean", "max")
+	for _, selection := range selected {
+		problem := selection.Problem
+		if err := pool.Use(conf.Limits.Merge(problem.Limits)); err != nil {
+			return nil, fmt.Errorf("start worker failed: %w", err)
+		}
+
+		fmt.Printf("%-5d %s\n", problem.Id, problem.Title)
+		for _, method := range selection.Methods {
+			bench := &BenchResult{
+				ProblemId: problem.Id,
+				Method:    method,
+				Status:    framework.StatusOK,
+			}
+
+			settings := conf.MethodSettings(problem, method)
+			bench.Budget = settings.MethodTimeout
+			repeat := settings.Repeat
+			if repeat < 1 {
+				repeat = 1
+			}
+
+			for i := 0; i < repeat; i++ {
+				result, err := runMethod(conf, pool, &isolate, problem, method)
+				if err != nil {
+					return nil, fmt.Errorf("restart worker failed: %w", err)
+				}
+
+				if result.Length() == 0 {
+					fmt.Fprintf(os.Stderr, "ERROR: run problem %d %s: %s\n", problem.Id, method, result.Message)
+					break
+				}
+
+				item := result.Results[0]
+				if !item.HasResult() {
+					bench.Status = item.Status
+					break
+				}
+
+				bench.Samples = append(bench.Samples, item.TimeCost)
+			}
+
+			printBenchResult(bench)
+			results = append(results, bench)
+		}
+	}
+
+	return results, nil
+}
+
+func printBenchResult(bench *BenchResult) {
+	parts := make([]string, 0, 5)
+	for _, d := range []time.Duration{bench.Min(), bench.Median(), bench.Mean(), bench.Max()} {
+		parts = append(parts, toMsColour(d, false, bench.Budget))
+	}
+
+	parts = append(parts, fmt.Sprintf("%d runs", len(bench.Samples)))
+	if bench.Status != framework.StatusOK {
+		parts = append(parts, color.YellowString(strings.TrimSpace(statusString(bench.Status))))
+	}
+
+	fmt.Printf("      + %-38s %s\n", rightPadding(bench.Method, 38, "."), strings.Join(parts, " "))
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// Command is a subcommand of projeuler, with its own flags.
+type Command struct {
+	Name     string
+	Args     string
+	Summary  string
+	Examples []string
+	Flags    func(fs *flag.FlagSet, conf *framework.Configure)
+	Run      func(conf *framework.Configure)
+}
+
+var commands []*Command
+
+func init() {
+	commands = []*Command{
+		{
+			Name:    "run",
+			Args:    "[selection...]",
+			Summary: "run solutions of problems, all problems are run if none is given",
+			Examples: []string{
+				"projeuler run",
+				"projeuler run 14 23.naive",
+				"projeuler run 1-50 '!23.naive'",
+				"projeuler run -isolate -transport unix 14",
+			},
+			Flags: reportFlags,
+			Run:   doRun,
+		},
+		{
+			Name:    "check",
+			Args:    "[selection...]",
+			Summary: "run solutions of problems and check results with answers",
+			Examples: []string{
+				"projeuler check",
+				"projeuler check -method-timeout 1s 14",
+				"projeuler check unsolved tag:primes",
+				"projeuler check -format junit -output report.xml",
+			},
+			Flags: checkFlags,
+			Run:   doCheck,
+		},
+		{
+			Name:    "bench",
+			Args:    "[selection...]",
+			Summary: "run each method several times and report statistics of time cost",
+			Examples: []string{
+				"projeuler bench 14",
+				"projeuler bench -count 10 -isolate '14.with-*'",
+				"projeuler bench -count 10 -save main",
+				"projeuler bench -count 10 -compare main -threshold 20",
+			},
+			Flags: benchFlags,
+			Run:   doBench,
+		},
+		{
+			Name:    "list",
+			Summary: "list all problems with their methods",
+			Examples: []string{
+				"projeuler list",
+			},
+			Flags: func(fs *flag.FlagSet, conf *framework.Configure) {},
+			Run:   doList,
+		},
+		{
+			Name:    "show",
+			Args:    "selection...",
+			Summary: "show details of problems, with their latest recorded timings",
+			Examples: []string{
+				"projeuler show 14",
+				"projeuler show --json 14 22",
+				"projeuler show tag:primes",
+			},
+			Flags: func(fs *flag.FlagSet, conf *framework.Configure) {
+				fs.BoolVar(&conf.JSONOutput, "json", false, "print each problem as a line of JSON")
+			},
+			Run: doShow,
+		},
+		{
+			Name:    "history",
+			Args:    "[selection...]",
+			Summary: "show trend of time cost of methods in recorded runs",
+			Examples: []string{
+				"projeuler history 14",
+				"projeuler history -by-commit 14.naive",
+				"projeuler history -json -limit 0 > history.json",
+			},
+			Flags: historyFlags,
+			Run:   doHistory,
+		},
+		{
+			Name:    "report",
+			Args:    "[selection...]",
+			Summary: "write a self-contained HTML report of recorded timings and history",
+			Examples: []string{
+				"projeuler check && projeuler report",
+				"projeuler report -output public/index.html tag:primes",
+			},
+			Flags: htmlReportFlags,
+			Run:   doReport,
+		},
+		{
+			Name:    "readme",
+			Summary: "regenerate progress table of problems in README.md",
+			Examples: []string{
+				"projeuler check && projeuler readme",
+				"projeuler readme -check",
+			},
+			Flags: readmeFlags,
+			Run:   doReadme,
+		},
+		{
+			Name:    "validate",
+			Summary: "check conventions of problem packages, reporting file and line of violations",
+			Examples: []string{
+				"projeuler validate",
+			},
+			Flags: validateFlags,
+			Run:   doValidate,
+		},
+		{
+			Name:    "worker",
+			Summary: "serve runs of methods for runner or client",
+			Examples: []string{
+				"projeuler worker -port 1707",
+				"projeuler worker -socket /tmp/projeuler.sock -concurrency 4",
+			},
+			Flags: workerFlags,
+			Run:   runWorker,
+		},
+		{
+			Name:    "client",
+			Args:    "selection...",
+			Summary: "run methods on a worker started by 'projeuler worker'",
+			Examples: []string{
+				"projeuler client -port 1707 14",
+				"projeuler client -socket /tmp/projeuler.sock -codec json 14.naive",
+			},
+			Flags: clientFlags,
+			Run:   doClient,
+		},
+		{
+			Name:    "config",
+			Summary: "print effective configuration, from project config file and flags",
+			Examples: []string{
+				"projeuler config",
+				"projeuler config -config ci.toml -method-timeout 1s",
+			},
+			Flags: configFlags,
+			Run:   doConfig,
+		},
+	}
+}
+
+const selectionHelp = `selection, all problems are selected if there is none:
+  14          all methods of problem 14
+  1-50        all methods of problems 1 to 50
+  1,5,9       problems 1, 5 and 9
+  14.naive    method naive of problem 14
+  14.with-*   methods of problem 14 matching the glob
+  unsolved    problems never recorded with the correct answer
+  noanswer    problems without known answer
+  slow        methods marked slow or recorded as slow
+  tag:primes  problems with tag primes
+  !23.naive   exclude an item, all problems are selected if there are only exclusions`
+
+func findCommand(name string) *Command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "usage: projeuler <command> [flags] [arguments]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.Name, cmd.Summary)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nRun 'projeuler help <command>' for flags and examples of a command.\n")
+}
+
+func (c *Command) FlagSet(conf *framework.Configure) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.Name, flag.ExitOnError)
+	c.Flags(fs, conf)
+	fs.BoolVar(&conf.DebugMode, "debug", false, "debug mode")
+	configFlag(fs, conf)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "usage: projeuler %s [flags] %s\n\n%s\n", c.Name, c.Args, c.Summary)
+		fmt.Fprintf(out, "\nflags:\n")
+		fs.PrintDefaults()
+		if strings.Contains(c.Args, "selection") {
+			fmt.Fprintf(out, "\n%s\n", selectionHelp)
+		}
+
+		fmt.Fprintf(out, "\nexamples:\n")
+		for _, example := range c.Examples {
+			fmt.Fprintf(out, "  %s\n", example)
+		}
+	}
+
+	return fs
+}
+
+// Execute parses arguments after command name, and runs the command.
+func (c *Command) Execute(args []string) {
+	conf := &framework.Configure{}
+	fs := c.FlagSet(conf)
+	conf.Problems = parseInterspersed(fs, args)
+	if err := applyProjectConfig(fs, conf); err != nil {
+		fmt.Fprintf(os.Stderr, "projeuler: %s\n", err)
+		os.Exit(ExitUsage)
+	}
+
+	initLogger(conf)
+	c.Run(conf)
+}
+
+// parseInterspersed parses flags mixed with positional arguments, like "show 14 --json", and
+// returns positional arguments. Arguments after "--" are all positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	positional := make([]string, 0, len(args))
+	for {
+		_ = fs.Parse(args)
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional
+		}
+
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...)
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func runnerFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.DurationVar(&conf.ProblemTimeout, "problem-timeout", 5*time.Second, "problem timeout")
+	fs.DurationVar(&conf.MethodTimeout, "method-timeout", 500*time.Millisecond,
+		"timeout of methods, budgets declared in problems are used instead unless it is given")
+	fs.DurationVar(&conf.TotalTimeout, "total-timeout", 0, "total timeout of -raw, 0 means no timeout")
+	fs.BoolVar(&conf.RawMode, "raw", false, "run solutions in this process, without any worker")
+	fs.StringVar(&conf.Transport, "transport", framework.TransportStdio,
+		"transport between runner and worker, stdio, unix or tcp")
+	fs.IntVar(&conf.SpareWorkers, "spare-workers", 1, "number of spare workers started in advance")
+	fs.BoolVar(&conf.Isolate, "isolate", false, "run each method in a freshly started worker")
+	fs.BoolVar(&conf.IncludeSlow, "include-slow", false, "run methods marked slow, which are skipped by default")
+	fs.DurationVar(&conf.ReadyTimeout, "ready-timeout", 5*time.Second, "time to wait a worker to be ready")
+	fs.DurationVar(&conf.ShutdownGrace, "shutdown-grace", time.Second, "grace period of worker shutdown")
+	limitFlags(fs, conf)
+}
+
+func reportFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	runnerFlags(fs, conf)
+	fs.StringVar(&conf.Format, "format", FormatTable, "output format, table, json, csv, markdown or junit")
+	fs.StringVar(&conf.Output, "output", "",
+		"file to write output in -format, the table is still printed on terminal if it is given")
+}
+
+func checkFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	reportFlags(fs, conf)
+	failOnFlag(fs, conf)
+}
+
+func benchFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	runnerFlags(fs, conf)
+	fs.IntVar(&conf.BenchCount, "count", 5, "number of runs of each method")
+	benchCompareFlags(fs, conf)
+}
+
+func limitFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.Func("limit-memory", "memory limit of worker process, like 512M, 0 means no limit", func(s string) error {
+		size, err := framework.ParseByteSize(s)
+		conf.Limits.Memory = size
+		return err
+	})
+	fs.DurationVar(&conf.Limits.CPUTime, "limit-cpu", 0, "CPU time limit of each method, run in its own worker, 0 means no limit")
+	fs.IntVar(&conf.Limits.OpenFiles, "limit-files", 0, "open files limit of worker process, 0 means no limit")
+}
+
+func configFlag(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.StringVar(&conf.ConfigFile, "config", "",
+		"project config file, projeuler.toml or projeuler.json in current directory by default")
+}
+
+func addressFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.IntVar(&conf.ServePort, "port", 1707, "TCP port of worker, 0 means any free port")
+	fs.StringVar(&conf.ServeSocket, "socket", "", "unix socket path of worker, override -port")
+	fs.StringVar(&conf.Codec, "codec", "", "message codec, binary or json, worker detects codec by default")
+}
+
+func workerFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	addressFlags(fs, conf)
+	fs.BoolVar(&conf.StdioMode, "stdio", false, "serve worker on stdin and stdout")
+	fs.IntVar(&conf.Concurrency, "concurrency", 1, "max number of runs in parallel")
+	fs.IntVar(&conf.ReadyFd, "ready-fd", 0, "file descriptor to report worker address on when ready")
+	fs.DurationVar(&conf.ShutdownGrace, "shutdown-grace", time.Second, "grace period of worker shutdown")
+	limitFlags(fs, conf)
+}
+
+func clientFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	addressFlags(fs, conf)
+}
+
+func doRun(conf *framework.Configure) {
+	if check := conf.Project.Check; check != nil && *check {
+		conf.CheckMode = true
+	}
+
+	if conf.RawMode {
+		doRunRaw(conf)
+		return
+	}
+
+	report, err := runProblems(conf, problems.Problems)
+	exitRun(conf, report, err)
+}
+
+func doCheck(conf *framework.Configure) {
+	conf.CheckMode = true
+	doRun(conf)
+}
+
+// doHelp prints usage of a command, or of projeuler if no command is given.
+func doHelp(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		return
+	}
+
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "projeuler: unknown command '%s'\n\n", args[0])
+		printUsage()
+		os.Exit(2)
+	}
+
+	cmd.FlagSet(&framework.Configure{}).Usage()
+}
+
+// isLegacyArgs tells whether arguments are in the old style without command, like
+// "projeuler -check 14".
+func isLegacyArgs(args []string) bool {
+	if len(args) == 0 || findCommand(args[0]) != nil {
+		return false
+	}
+
+	first := args[0]
+	if first == "-h" || first == "-help" || first == "--help" {
+		return false
+	}
+
+	if strings.HasPrefix(first, "-") {
+		return true
+	}
+
+	_, err := framework.ParseSelection(args[:1])
+	return err == nil
+}
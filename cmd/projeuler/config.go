@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// applyProjectConfig loads project config in -config, and applies its defaults to flags not given
+// on command line. Defaults of flags not in fs are ignored. Flags given are kept in configure, so
+// overrides of problems do not replace them either.
+func applyProjectConfig(fs *flag.FlagSet, conf *framework.Configure) error {
+	project, err := framework.LoadProjectConfig(conf.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	for _, value := range project.FlagValues() {
+		if given[value.Name] || fs.Lookup(value.Name) == nil {
+			continue
+		}
+
+		if err := fs.Set(value.Name, value.Value); err != nil {
+			return fmt.Errorf("%s: invalid %s '%s': %w", project.File, value.Name, value.Value, err)
+		}
+	}
+
+	conf.Project = project
+	conf.GivenFlags = given
+	return nil
+}
+
+func configFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	checkFlags(fs, conf)
+	addressFlags(fs, conf)
+	fs.IntVar(&conf.BenchCount, "count", 5, "number of runs of each method in bench")
+}
+
+// EffectiveConfig is configuration after flags are applied on project config.
+type EffectiveConfig struct {
+	File           string                           `json:"file,omitempty"`
+	ProblemTimeout framework.Duration               `json:"problem_timeout"`
+	MethodTimeout  framework.Duration               `json:"method_timeout"`
+	TotalTimeout   framework.Duration               `json:"total_timeout"`
+	Port           int                              `json:"port"`
+	Socket         string                           `json:"socket,omitempty"`
+	Transport      string                           `json:"transport"`
+	SpareWorkers   int                              `json:"spare_workers"`
+	Isolate        bool                             `json:"isolate"`
+	Check          bool                             `json:"check"`
+	FailOn         string                           `json:"fail_on"`
+	Format         string                           `json:"format"`
+	Count          int                              `json:"count"`
+	Methods        map[string]EffectiveMethodConfig `json:"methods,omitempty"`
+}
+
+// EffectiveMethodConfig is settings of a method overridden in project config.
+type EffectiveMethodConfig struct {
+	ProblemTimeout framework.Duration `json:"problem_timeout"`
+	MethodTimeout  framework.Duration `json:"method_timeout"`
+	Skip           bool               `json:"skip"`
+	ExpectedSlow   bool               `json:"expected_slow"`
+	Repeat         int                `json:"repeat"`
+}
+
+func NewEffectiveConfig(conf *framework.Configure) *EffectiveConfig {
+	c := &EffectiveConfig{
+		File:           conf.Project.File,
+		ProblemTimeout: framework.Duration(conf.ProblemTimeout),
+		MethodTimeout:  framework.Duration(conf.MethodTimeout),
+		TotalTimeout:   framework.Duration(conf.TotalTimeout),
+		Port:           conf.ServePort,
+		Socket:         conf.ServeSocket,
+		Transport:      conf.Transport,
+		SpareWorkers:   conf.SpareWorkers,
+		Isolate:        conf.Isolate,
+		Check:          conf.Project.Check != nil && *conf.Project.Check,
+		FailOn:         strings.Join(conf.FailOn, ","),
+		Format:         conf.Format,
+		Count:          conf.BenchCount,
+		Methods:        make(map[string]EffectiveMethodConfig),
+	}
+
+	for _, problem := range problems.Problems {
+		if conf.Project.Problems[problem.Id] == nil {
+			continue
+		}
+
+		for _, method := range problem.MethodList() {
+			s := conf.MethodSettings(problem, method)
+			c.Methods[fmt.Sprintf("%d.%s", problem.Id, method)] = EffectiveMethodConfig{
+				ProblemTimeout: framework.Duration(s.ProblemTimeout),
+				MethodTimeout:  framework.Duration(s.MethodTimeout),
+				Skip:           s.Skip,
+				ExpectedSlow:   s.ExpectedSlow,
+				Repeat:         s.Repeat,
+			}
+		}
+	}
+
+	return c
+}
+
+// doConfig prints effective configuration, with settings of each method overridden in project
+// config. Problems and methods in project config but not found are warned.
+func doConfig(conf *framework.Configure) {
+	for _, err := range conf.Project.CheckProblems(problems.Problems) {
+		fmt.Fprintf(os.Stderr, "WARNING: %s: %s\n", conf.Project.File, err)
+	}
+
+	data, _ := json.MarshalIndent(NewEffectiveConfig(conf), "", "  ")
+	fmt.Println(string(data))
+}
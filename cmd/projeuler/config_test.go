@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+func TestApplyProjectConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "projeuler.toml")
+	err := os.WriteFile(file, []byte(`
+method_timeout = "1s"
+problem_timeout = "20s"
+
+[problems.14]
+method_timeout = "2s"
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		args           []string
+		problemTimeout time.Duration
+		methodTimeout  time.Duration
+		override       time.Duration
+	}{
+		{[]string{}, 20 * time.Second, time.Second, 2 * time.Second},
+		{[]string{"-method-timeout", "3s"}, 20 * time.Second, 3 * time.Second, 3 * time.Second},
+		{[]string{"-problem-timeout=30s"}, 30 * time.Second, time.Second, 2 * time.Second},
+	}
+
+	for _, c := range cases {
+		conf := &framework.Configure{}
+		fs := findCommand("check").FlagSet(conf)
+		parseInterspersed(fs, append([]string{"-config", file}, c.args...))
+		if err := applyProjectConfig(fs, conf); err != nil {
+			t.Fatalf("apply config with %v failed: %s", c.args, err)
+		}
+
+		if conf.ProblemTimeout != c.problemTimeout || conf.MethodTimeout != c.methodTimeout {
+			t.Errorf("timeouts with %v: expected %s and %s, got %s and %s", c.args,
+				c.problemTimeout, c.methodTimeout, conf.ProblemTimeout, conf.MethodTimeout)
+		}
+
+		settings := conf.MethodSettings(framework.Problem{Id: 14}, "naive")
+		if settings.MethodTimeout != c.override || settings.ProblemTimeout != c.problemTimeout {
+			t.Errorf("timeouts of 14.naive with %v: expected %s and %s, got %s and %s", c.args,
+				c.problemTimeout, c.override, settings.ProblemTimeout, settings.MethodTimeout)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// Exit status of projeuler. If methods fail in several ways, the first one in -fail-on order
+// wrong, crash, limit, timeout decides the exit status.
+const (
+	// ExitOK means all methods are correct, or they fail only in ways not in -fail-on.
+	ExitOK = 0
+	// ExitWrong means some method returns a wrong answer.
+	ExitWrong = 1
+	// ExitUsage means arguments are invalid.
+	ExitUsage = 2
+	// ExitTimeout means some method times out.
+	ExitTimeout = 3
+	// ExitCrash means some method crashes its worker, or worker can not be started.
+	ExitCrash = 4
+	// ExitLimit means some method exceeds resource limits.
+	ExitLimit = 5
+	// ExitRegression means bench finds some method slower than baseline.
+	ExitRegression = 6
+)
+
+// Kinds of failure which can be given in -fail-on.
+const (
+	FailWrong   = "wrong"
+	FailCrash   = "crash"
+	FailLimit   = "limit"
+	FailTimeout = "timeout"
+)
+
+var failExitCodes = []struct {
+	kind string
+	code int
+}{
+	{FailWrong, ExitWrong},
+	{FailCrash, ExitCrash},
+	{FailLimit, ExitLimit},
+	{FailTimeout, ExitTimeout},
+}
+
+// DefaultFailOn includes all failures, timeouts of methods expected slow are not counted anyway.
+const DefaultFailOn = "wrong,crash,limit,timeout"
+
+// ParseFailOn parses comma separated kinds of failure, empty string means never fail.
+func ParseFailOn(s string) ([]string, error) {
+	result := make([]string, 0, len(failExitCodes))
+	for _, kind := range strings.Split(s, ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+
+		found := false
+		for _, f := range failExitCodes {
+			found = found || f.kind == kind
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown failure '%s', wrong, crash, limit or timeout expected", kind)
+		}
+
+		result = append(result, kind)
+	}
+
+	return result, nil
+}
+
+// UsageError is an error in arguments, projeuler exits with ExitUsage on it.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// errorExitCode prints error to stderr and returns exit status for it.
+func errorExitCode(err error) int {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+	usage := &UsageError{}
+	if errors.As(err, &usage) {
+		return ExitUsage
+	}
+
+	return ExitCrash
+}
+
+// exitRun exits after running problems. In check mode, a summary of methods is printed, and
+// exit status tells failures in -fail-on. The summary goes to stderr if the report is written
+// to stdout.
+func exitRun(conf *framework.Configure, report *Report, err error) {
+	if err != nil {
+		os.Exit(errorExitCode(err))
+	}
+
+	if !conf.CheckMode {
+		return
+	}
+
+	summary := report.Summary(conf)
+	var w io.Writer = os.Stdout
+	if !printsTable(conf) {
+		w = os.Stderr
+	}
+
+	fmt.Fprintf(w, "summary: %s\n", summary)
+	if code := summary.ExitCode(conf.FailOn); code != ExitOK {
+		os.Exit(code)
+	}
+}
+
+// failOnFlag adds -fail-on to fs.
+func failOnFlag(fs *flag.FlagSet, conf *framework.Configure) {
+	conf.FailOn, _ = ParseFailOn(DefaultFailOn)
+	fs.Func("fail-on", "comma separated failures to exit with error in check mode, "+
+		"of wrong, crash, limit and timeout (default \""+DefaultFailOn+"\")", func(s string) error {
+		failOn, err := ParseFailOn(s)
+		conf.FailOn = failOn
+		return err
+	})
+}
+
+// Summary counts methods by their final state.
+type Summary struct {
+	Correct int
+	Wrong   int
+	Unknown int
+	Timeout int
+	// ExpectedSlow is number of timeouts of methods expected slow, they are not failures.
+	ExpectedSlow int
+	Crashed      int
+	Limit        int
+}
+
+func (r *Report) Summary(conf *framework.Configure) Summary {
+	s := Summary{}
+	for _, record := range r.Records {
+		switch {
+		case record.Status == framework.StatusTimeout:
+			s.Timeout++
+			problem, _ := problems.GetProblem(record.Problem)
+			if conf.MethodSettings(problem, record.Method).ExpectedSlow {
+				s.ExpectedSlow++
+			}
+
+		case record.Status == framework.StatusCrashed:
+			s.Crashed++
+
+		case record.Status == framework.StatusMemoryLimit, record.Status == framework.StatusCPULimit:
+			s.Limit++
+
+		case record.Verdict == framework.VerdictCorrect:
+			s.Correct++
+
+		case record.Verdict == framework.VerdictWrong:
+			s.Wrong++
+
+		default:
+			s.Unknown++
+		}
+	}
+
+	return s
+}
+
+func (s Summary) count(kind string) int {
+	switch kind {
+	case FailWrong:
+		return s.Wrong
+
+	case FailCrash:
+		return s.Crashed
+
+	case FailLimit:
+		return s.Limit
+
+	case FailTimeout:
+		return s.Timeout - s.ExpectedSlow
+
+	default:
+		return 0
+	}
+}
+
+// ExitCode returns exit status for failures in failOn.
+func (s Summary) ExitCode(failOn []string) int {
+	for _, f := range failExitCodes {
+		for _, kind := range failOn {
+			if kind == f.kind && s.count(kind) > 0 {
+				return f.code
+			}
+		}
+	}
+
+	return ExitOK
+}
+
+func (s Summary) String() string {
+	timeout := fmt.Sprintf("%d timeout", s.Timeout)
+	if s.ExpectedSlow > 0 {
+		timeout += fmt.Sprintf(" (%d expected slow)", s.ExpectedSlow)
+	}
+
+	return fmt.Sprintf("%d correct, %d wrong, %s, %d crashed, %d limit exceeded, %d unknown",
+		s.Correct, s.Wrong, timeout, s.Crashed, s.Limit, s.Unknown)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+func TestParseFailOn(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected []string
+		err      string
+	}{
+		{DefaultFailOn, []string{FailWrong, FailCrash, FailLimit, FailTimeout}, ""},
+		{"wrong,crash,limit", []string{FailWrong, FailCrash, FailLimit}, ""},
+		{" timeout , wrong ", []string{FailTimeout, FailWrong}, ""},
+		{"", []string{}, ""},
+		{",,", []string{}, ""},
+		{"wrong,slow", nil, "unknown failure 'slow'"},
+		{"Wrong", nil, "unknown failure 'Wrong'"},
+	}
+
+	for _, c := range cases {
+		got, err := ParseFailOn(c.s)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("ParseFailOn(%q) got error %v, expected %q", c.s, err, c.err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseFailOn(%q) failed: %v", c.s, err)
+
+		} else if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("ParseFailOn(%q) got %v, expected %v", c.s, got, c.expected)
+		}
+	}
+}
+
+func TestFailOnFlag(t *testing.T) {
+	conf := &framework.Configure{}
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	failOnFlag(fs, conf)
+	expected := []string{FailWrong, FailCrash, FailLimit, FailTimeout}
+	if !reflect.DeepEqual(conf.FailOn, expected) {
+		t.Errorf("default -fail-on is %v, expected %v", conf.FailOn, expected)
+	}
+
+	if err := fs.Parse([]string{"-fail-on", "wrong,crash"}); err != nil {
+		t.Fatalf("parse -fail-on failed: %v", err)
+	}
+
+	if expected = []string{FailWrong, FailCrash}; !reflect.DeepEqual(conf.FailOn, expected) {
+		t.Errorf("-fail-on wrong,crash got %v, expected %v", conf.FailOn, expected)
+	}
+
+	if err := fs.Parse([]string{"-fail-on", "wrong,unknown"}); err == nil {
+		t.Errorf("-fail-on with unknown failure is accepted")
+	}
+}
+
+func TestSummaryExitCode(t *testing.T) {
+	defaultFailOn, _ := ParseFailOn(DefaultFailOn)
+	withoutTimeout, _ := ParseFailOn("wrong,crash,limit")
+	cases := []struct {
+		summary  Summary
+		failOn   []string
+		expected int
+	}{
+		{Summary{Correct: 3, Unknown: 1}, defaultFailOn, ExitOK},
+		{Summary{Correct: 1, Wrong: 1}, defaultFailOn, ExitWrong},
+		{Summary{Crashed: 1}, defaultFailOn, ExitCrash},
+		{Summary{Limit: 1}, defaultFailOn, ExitLimit},
+		{Summary{Timeout: 2}, defaultFailOn, ExitTimeout},
+		{Summary{Timeout: 2}, withoutTimeout, ExitOK},
+		{Summary{Timeout: 2, ExpectedSlow: 2}, defaultFailOn, ExitOK},
+		{Summary{Timeout: 2, ExpectedSlow: 1}, defaultFailOn, ExitTimeout},
+		{Summary{Wrong: 1, Crashed: 1, Limit: 1, Timeout: 1}, defaultFailOn, ExitWrong},
+		{Summary{Crashed: 1, Limit: 1, Timeout: 1}, defaultFailOn, ExitCrash},
+		{Summary{Limit: 1, Timeout: 1}, defaultFailOn, ExitLimit},
+		{Summary{Wrong: 1, Timeout: 1}, []string{FailTimeout}, ExitTimeout},
+		{Summary{Wrong: 1, Crashed: 1}, []string{}, ExitOK},
+	}
+
+	for _, c := range cases {
+		if got := c.summary.ExitCode(c.failOn); got != c.expected {
+			t.Errorf("exit code of %s on %v is %d, expected %d", c.summary, c.failOn, got, c.expected)
+		}
+	}
+}
+
+func TestErrorExitCode(t *testing.T) {
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer stderr.Close()
+	saved := os.Stderr
+	os.Stderr = stderr
+	defer func() { os.Stderr = saved }()
+
+	usage := fmt.Errorf("bad flag: %w", &UsageError{errors.New("unknown problem 0")})
+	if code := errorExitCode(usage); code != ExitUsage {
+		t.Errorf("exit code of usage error is %d, expected %d", code, ExitUsage)
+	}
+
+	if code := errorExitCode(errors.New("worker failed")); code != ExitCrash {
+		t.Errorf("exit code of other error is %d, expected %d", code, ExitCrash)
+	}
+
+	os.Stderr = saved
+	output, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "ERROR: bad flag: unknown problem 0\nERROR: worker failed\n"
+	if string(output) != expected {
+		t.Errorf("errors printed to stderr: %q, expected %q", output, expected)
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// historyFile keeps all runs, a run in a line of JSON, in stateDir.
+const historyFile = "history.jsonl"
+
+// Environment is where a run happens.
+type Environment struct {
+	Commit    string `json:"commit,omitempty"`
+	Dirty     bool   `json:"dirty,omitempty"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	CPUs      int    `json:"cpus"`
+	Host      string `json:"host,omitempty"`
+}
+
+// CurrentEnvironment returns environment of this process. Commit is the one binary is built
+// from, or the checked out one if binary has no version control information, like in go run.
+func CurrentEnvironment() Environment {
+	env := Environment{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+	}
+
+	env.Host, _ = os.Hostname()
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				env.Commit = shortCommit(setting.Value)
+
+			case "vcs.modified":
+				env.Dirty = setting.Value == "true"
+			}
+		}
+	}
+
+	if env.Commit == "" {
+		if out, err := exec.Command("git", "rev-parse", "HEAD").Output(); err == nil {
+			env.Commit = shortCommit(strings.TrimSpace(string(out)))
+			out, err = exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+			env.Dirty = err == nil && len(out) > 0
+		}
+	}
+
+	return env
+}
+
+// CommitLabel is commit, with "+" if there are uncommitted changes.
+func (e Environment) CommitLabel() string {
+	if e.Commit == "" {
+		return "-"
+	}
+
+	if e.Dirty {
+		return e.Commit + "+"
+	}
+
+	return e.Commit
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+
+	return commit
+}
+
+// HistoryRun is a run of runner, with results of all methods run.
+type HistoryRun struct {
+	StartedAt   time.Time   `json:"started_at"`
+	Command     string      `json:"command"`
+	Environment Environment `json:"environment"`
+	Records     []RunRecord `json:"records"`
+}
+
+// AppendHistory appends run to history file.
+func AppendHistory(run *HistoryRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Join(stateDir, historyFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// appendRunHistory appends methods run in report to history, nothing is appended if no method
+// is run.
+func appendRunHistory(conf *framework.Configure, startedAt time.Time, report *Report) error {
+	if len(report.Records) == 0 {
+		return nil
+	}
+
+	command := "run"
+	if conf.CheckMode {
+		command = "check"
+	}
+
+	return AppendHistory(&HistoryRun{
+		StartedAt:   startedAt,
+		Command:     command,
+		Environment: CurrentEnvironment(),
+		Records:     report.Records,
+	})
+}
+
+// LoadHistory reads all runs in history file, in order they are run. Lines can not be parsed,
+// like one written partially, are skipped.
+func LoadHistory() ([]HistoryRun, error) {
+	runs := make([]HistoryRun, 0)
+	file, err := os.Open(filepath.Join(stateDir, historyFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return runs, nil
+
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		run := HistoryRun{}
+		if err := json.Unmarshal(scanner.Bytes(), &run); err == nil {
+			runs = append(runs, run)
+		}
+	}
+
+	return runs, scanner.Err()
+}
+
+// HistoryPoint is a run of a method in history.
+type HistoryPoint struct {
+	Problem   int                    `json:"problem"`
+	Method    string                 `json:"method"`
+	StartedAt time.Time              `json:"started_at"`
+	Commit    string                 `json:"commit,omitempty"`
+	Dirty     bool                   `json:"dirty,omitempty"`
+	Status    framework.ResultStatus `json:"status"`
+	Verdict   framework.Verdict      `json:"verdict"`
+	Result    *int64                 `json:"result"`
+	TimeCost  time.Duration          `json:"time_ns"`
+	// Runs is number of runs merged in the point, by commit.
+	Runs int `json:"runs"`
+}
+
+// CommitLabel is commit of point, with "+" if there are uncommitted changes.
+func (p *HistoryPoint) CommitLabel() string {
+	return Environment{Commit: p.Commit, Dirty: p.Dirty}.CommitLabel()
+}
+
+// methodHistory returns points of method in runs, in order they are run.
+func methodHistory(runs []HistoryRun, problemId int, method string) []HistoryPoint {
+	points := make([]HistoryPoint, 0)
+	for _, run := range runs {
+		for _, record := range run.Records {
+			if record.Problem != problemId || record.Method != method {
+				continue
+			}
+
+			points = append(points, HistoryPoint{
+				Problem:   problemId,
+				Method:    method,
+				StartedAt: run.StartedAt,
+				Commit:    run.Environment.Commit,
+				Dirty:     run.Environment.Dirty,
+				Status:    record.Status,
+				Verdict:   record.Verdict,
+				Result:    record.Result,
+				TimeCost:  record.TimeCost,
+				Runs:      1,
+			})
+		}
+	}
+
+	return points
+}
+
+// byCommit merges consecutive points of the same commit into one, keeping the fastest successful
+// run, so that trend is shown across commits.
+func byCommit(points []HistoryPoint) []HistoryPoint {
+	result := make([]HistoryPoint, 0, len(points))
+	for _, point := range points {
+		if n := len(result); n > 0 && result[n-1].CommitLabel() == point.CommitLabel() {
+			last := &result[n-1]
+			runs := last.Runs + 1
+			if last.Status != framework.StatusOK ||
+				point.Status == framework.StatusOK && point.TimeCost < last.TimeCost {
+				*last = point
+			}
+
+			last.Runs = runs
+			continue
+		}
+
+		result = append(result, point)
+	}
+
+	return result
+}
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws time costs of points, scaled from the fastest to the slowest successful run.
+// Runs without result are drawn as "x".
+func sparkline(points []HistoryPoint) string {
+	var min, max time.Duration = -1, 0
+	for _, point := range points {
+		if point.Status != framework.StatusOK {
+			continue
+		}
+
+		if min < 0 || point.TimeCost < min {
+			min = point.TimeCost
+		}
+
+		if point.TimeCost > max {
+			max = point.TimeCost
+		}
+	}
+
+	var b strings.Builder
+	for _, point := range points {
+		switch {
+		case point.Status != framework.StatusOK:
+			b.WriteRune('x')
+
+		case max == min:
+			b.WriteRune(sparkBars[len(sparkBars)/2])
+
+		default:
+			level := int(point.TimeCost-min) * (len(sparkBars) - 1) / int(max-min)
+			b.WriteRune(sparkBars[level])
+		}
+	}
+
+	return b.String()
+}
+
+func historyFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.BoolVar(&conf.JSONOutput, "json", false, "print runs of methods as JSON")
+	fs.BoolVar(&conf.HistoryByCommit, "by-commit", false, "merge runs of the same commit, keeping the fastest")
+	fs.IntVar(&conf.HistoryLimit, "limit", 30, "number of latest runs of each method, 0 means all")
+}
+
+// doHistory shows trend of time cost of selected methods. A sparkline is printed for each
+// method, and every run is listed if only one method is selected.
+func doHistory(conf *framework.Configure) {
+	runs, err := LoadHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: load history failed: %s\n", err)
+		os.Exit(ExitCrash)
+	}
+
+	selection, err := framework.ParseSelection(conf.Problems)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		os.Exit(ExitUsage)
+	}
+
+	selected, err := selection.Resolve(problems.Problems, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		os.Exit(ExitUsage)
+	}
+
+	all := make([]HistoryPoint, 0)
+	methods := 0
+	for _, s := range selected {
+		title := false
+		for _, method := range s.Methods {
+			points := methodHistory(runs, s.Problem.Id, method)
+			if conf.HistoryByCommit {
+				points = byCommit(points)
+			}
+
+			if conf.HistoryLimit > 0 && len(points) > conf.HistoryLimit {
+				points = points[len(points)-conf.HistoryLimit:]
+			}
+
+			if len(points) == 0 {
+				continue
+			}
+
+			methods++
+			all = append(all, points...)
+			if conf.JSONOutput {
+				continue
+			}
+
+			if !title {
+				fmt.Printf("%-5d %s\n", s.Problem.Id, s.Problem.Title)
+				title = true
+			}
+
+			printHistorySummary(method, points)
+		}
+	}
+
+	if conf.JSONOutput {
+		data, _ := json.MarshalIndent(all, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	if methods == 0 {
+		fmt.Printf("no run recorded in %s\n", filepath.Join(stateDir, historyFile))
+
+	} else if methods == 1 {
+		fmt.Println()
+		printHistoryPoints(all)
+	}
+}
+
+func printHistorySummary(method string, points []HistoryPoint) {
+	last := points[len(points)-1]
+	_, lastCost := toMsString(last.TimeCost)
+	if last.Status != framework.StatusOK {
+		lastCost = fmt.Sprintf("%12s", last.Status)
+	}
+
+	fmt.Printf("      + %-38s %s last %s, %d runs\n", rightPadding(method, 38, "."),
+		sparkline(points), lastCost, len(points))
+}
+
+func printHistoryPoints(points []HistoryPoint) {
+	fmt.Printf("%-19s %-13s %-12s %-10s %12s\n", "time", "commit", "status", "verdict", "time cost")
+	for _, point := range points {
+		_, timeCost := toMsString(point.TimeCost)
+		runs := ""
+		if point.Runs > 1 {
+			runs = fmt.Sprintf(" (best of %d)", point.Runs)
+		}
+
+		fmt.Printf("%-19s %-13s %-12s %-10s %s%s\n", point.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			point.CommitLabel(), point.Status, point.Verdict, timeCost, runs)
+	}
+}
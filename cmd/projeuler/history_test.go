@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+func makeHistoryPoint(commit string, dirty bool, status framework.ResultStatus, ms int) HistoryPoint {
+	return HistoryPoint{
+		Commit:   commit,
+		Dirty:    dirty,
+		Status:   status,
+		TimeCost: time.Duration(ms) * time.Millisecond,
+		Runs:     1,
+	}
+}
+
+func TestByCommit(t *testing.T) {
+	points := []HistoryPoint{
+		makeHistoryPoint("c1", false, framework.StatusOK, 30),
+		makeHistoryPoint("c1", false, framework.StatusOK, 20),
+		makeHistoryPoint("c1", false, framework.StatusTimeout, 500),
+		makeHistoryPoint("c2", false, framework.StatusTimeout, 500),
+		makeHistoryPoint("c2", false, framework.StatusOK, 50),
+		makeHistoryPoint("c2", true, framework.StatusOK, 40),
+		makeHistoryPoint("c1", false, framework.StatusOK, 10),
+		makeHistoryPoint("c3", false, framework.StatusTimeout, 500),
+		makeHistoryPoint("c3", false, framework.StatusCrashed, 1),
+	}
+
+	expected := []struct {
+		commit string
+		status framework.ResultStatus
+		ms     int
+		runs   int
+	}{
+		{"c1", framework.StatusOK, 20, 3},
+		{"c2", framework.StatusOK, 50, 2},
+		{"c2+", framework.StatusOK, 40, 1},
+		{"c1", framework.StatusOK, 10, 1},
+		{"c3", framework.StatusCrashed, 1, 2},
+	}
+
+	merged := byCommit(points)
+	if len(merged) != len(expected) {
+		t.Fatalf("expected %d points, got %d: %+v", len(expected), len(merged), merged)
+	}
+
+	for i, e := range expected {
+		got := merged[i]
+		if got.CommitLabel() != e.commit || got.Status != e.status ||
+			got.TimeCost != time.Duration(e.ms)*time.Millisecond || got.Runs != e.runs {
+			t.Errorf("point %d: expected %s %s %dms of %d runs, got %s %s %s of %d runs", i,
+				e.commit, e.status, e.ms, e.runs, got.CommitLabel(), got.Status, got.TimeCost, got.Runs)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	ok := func(ms int) HistoryPoint {
+		return makeHistoryPoint("c1", false, framework.StatusOK, ms)
+	}
+
+	timeout := makeHistoryPoint("c1", false, framework.StatusTimeout, 500)
+	crashed := makeHistoryPoint("c1", false, framework.StatusCrashed, 1)
+	cases := []struct {
+		points   []HistoryPoint
+		expected string
+	}{
+		{[]HistoryPoint{}, ""},
+		{[]HistoryPoint{ok(10)}, "▅"},
+		{[]HistoryPoint{ok(10), ok(10)}, "▅▅"},
+		{[]HistoryPoint{ok(10), ok(80)}, "▁█"},
+		{[]HistoryPoint{ok(10), ok(45), ok(80), ok(20)}, "▁▄█▂"},
+		{[]HistoryPoint{timeout, ok(10), crashed, ok(80)}, "x▁x█"},
+		{[]HistoryPoint{timeout, ok(10)}, "x▅"},
+		{[]HistoryPoint{timeout, crashed}, "xx"},
+	}
+
+	for _, c := range cases {
+		if got := sparkline(c.points); got != c.expected {
+			t.Errorf("sparkline of %+v: expected %q, got %q", c.points, c.expected, got)
+		}
+	}
+}
+
+func TestLoadHistory(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = os.Chdir(wd) }()
+
+	if runs, err := LoadHistory(); err != nil || len(runs) != 0 {
+		t.Fatalf("history without file should be empty, got %d runs, error %v", len(runs), err)
+	}
+
+	for _, command := range []string{"run", "check"} {
+		err := AppendHistory(&HistoryRun{
+			Command:     command,
+			Environment: Environment{Commit: "c1"},
+			Records:     []RunRecord{{Problem: 14, Method: "naive", Status: framework.StatusOK}},
+		})
+		if err != nil {
+			t.Fatalf("append history failed: %s", err)
+		}
+	}
+
+	// A run written partially, like one interrupted.
+	file, err := os.OpenFile(filepath.Join(stateDir, historyFile), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = file.WriteString(`{"started_at":"2024-01-02T03:04:05Z","command":"run","records":[{"prob`)
+	_ = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := LoadHistory()
+	if err != nil {
+		t.Fatalf("load history failed: %s", err)
+	}
+
+	if len(runs) != 2 || runs[0].Command != "run" || runs[1].Command != "check" ||
+		len(runs[1].Records) != 1 || runs[1].Records[0].Method != "naive" {
+		t.Errorf("expected runs of run and check, got %+v", runs)
+	}
+}
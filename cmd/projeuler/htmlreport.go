@@ -0,0 +1,348 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// Size of charts in HTML report, in pixels.
+const (
+	barChartWidth      = 640
+	barChartBarHeight  = 18
+	barChartLabelWidth = 180
+	historyChartWidth  = 640
+	historyChartHeight = 160
+	historyChartPoints = 30
+)
+
+// verdictColours are colours of verdicts in HTML report.
+var verdictColours = map[framework.Verdict]string{
+	framework.VerdictCorrect:  "#2e9d4b",
+	framework.VerdictWrong:    "#d43c3c",
+	framework.VerdictUnknown:  "#d9a21b",
+	framework.VerdictNoResult: "#8a8a8a",
+}
+
+// historyColours are colours of methods in history chart, in order of method names.
+var historyColours = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b"}
+
+// HTMLMethod is a method in HTML report, from its latest recorded run.
+type HTMLMethod struct {
+	Name       string
+	Recorded   bool
+	Result     string
+	Status     framework.ResultStatus
+	Verdict    framework.Verdict
+	TimeCost   time.Duration
+	Budget     time.Duration
+	Slow       bool
+	RecordedAt time.Time
+	History    []HistoryPoint
+}
+
+func (m *HTMLMethod) Colour() string {
+	return verdictColours[m.Verdict]
+}
+
+func (m *HTMLMethod) Time() string {
+	if !m.Recorded {
+		return ""
+	}
+
+	_, s := toMsString(m.TimeCost)
+	return strings.TrimSpace(s)
+}
+
+// HTMLProblem is a problem in HTML report.
+type HTMLProblem struct {
+	Id       int
+	Title    string
+	Answer   string
+	Methods  []*HTMLMethod
+	BarChart template.HTML
+	History  template.HTML
+}
+
+// HTMLReport is everything in HTML report.
+type HTMLReport struct {
+	Title       string
+	GeneratedAt time.Time
+	Environment Environment
+	Summary     map[string]int
+	Problems    []*HTMLProblem
+}
+
+func htmlReportFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.StringVar(&conf.Output, "output", "report.html", "file to write HTML report to, - means stdout")
+}
+
+// NewHTMLReport builds report of selected problems from recorded timings and history.
+func NewHTMLReport(selected []framework.ProblemSelection, timings Timings, runs []HistoryRun) *HTMLReport {
+	report := &HTMLReport{
+		Title:       "projeuler.go report",
+		GeneratedAt: time.Now(),
+		Environment: CurrentEnvironment(),
+		Summary:     make(map[string]int),
+	}
+
+	for _, s := range selected {
+		problem := &HTMLProblem{
+			Id:     s.Problem.Id,
+			Title:  s.Problem.Title,
+			Answer: "unknown",
+		}
+
+		if !s.Problem.NoAnswer {
+			problem.Answer = fmt.Sprintf("%d", s.Problem.Answer)
+		}
+
+		for _, name := range s.Methods {
+			method := &HTMLMethod{
+				Name:    name,
+				Budget:  s.Problem.Budget(name),
+				Slow:    s.Problem.IsSlow(name),
+				Verdict: framework.VerdictNoResult,
+				History: methodHistory(runs, s.Problem.Id, name),
+			}
+
+			if record, found := timings.Get(s.Problem.Id, name); found {
+				item := framework.ResultItem{Status: record.Status, Result: record.Result}
+				method.Recorded = true
+				method.Status = record.Status
+				method.Verdict = s.Problem.Verdict(item)
+				method.TimeCost = record.TimeCost
+				method.RecordedAt = record.RecordedAt
+				if item.HasResult() {
+					method.Result = fmt.Sprintf("%d", record.Result)
+				}
+
+				report.Summary[string(method.Verdict)]++
+			}
+
+			if len(method.History) > historyChartPoints {
+				method.History = method.History[len(method.History)-historyChartPoints:]
+			}
+
+			problem.Methods = append(problem.Methods, method)
+		}
+
+		problem.BarChart = barChartSVG(problem.Methods)
+		problem.History = historyChartSVG(problem.Methods)
+		report.Problems = append(report.Problems, problem)
+	}
+
+	return report
+}
+
+// barChartSVG draws latest time cost of methods as horizontal bars, scaled to the slowest one.
+func barChartSVG(methods []*HTMLMethod) template.HTML {
+	var max time.Duration
+	for _, m := range methods {
+		if m.Recorded && m.TimeCost > max {
+			max = m.TimeCost
+		}
+	}
+
+	if max == 0 {
+		return ""
+	}
+
+	barWidth := barChartWidth - barChartLabelWidth - 90
+	height := len(methods) * (barChartBarHeight + 6)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg width="%d" height="%d" viewBox="0 0 %d %d" role="img">`,
+		barChartWidth, height, barChartWidth, height)
+	for i, m := range methods {
+		y := i * (barChartBarHeight + 6)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`,
+			barChartLabelWidth-8, y+barChartBarHeight-4, template.HTMLEscapeString(m.Name))
+		if !m.Recorded {
+			continue
+		}
+
+		width := int(float64(barWidth) * float64(m.TimeCost) / float64(max))
+		if width < 1 {
+			width = 1
+		}
+
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s: %s %s</title></rect>`,
+			barChartLabelWidth, y, width, barChartBarHeight, m.Colour(),
+			template.HTMLEscapeString(m.Name), m.Time(), m.Verdict)
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`,
+			barChartLabelWidth+width+6, y+barChartBarHeight-4, m.Time())
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// historyChartSVG draws time cost of methods in recorded runs as lines, one for each method.
+// Nothing is drawn if no method has more than one run in history.
+func historyChartSVG(methods []*HTMLMethod) template.HTML {
+	var max time.Duration
+	longest := 0
+	for _, m := range methods {
+		for _, point := range m.History {
+			if point.Status == framework.StatusOK && point.TimeCost > max {
+				max = point.TimeCost
+			}
+		}
+
+		if len(m.History) > longest {
+			longest = len(m.History)
+		}
+	}
+
+	if max == 0 || longest < 2 {
+		return ""
+	}
+
+	const left, bottom, top = 70, 20, 10
+	plotWidth := historyChartWidth - left - 10
+	plotHeight := historyChartHeight - bottom - top
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg width="%d" height="%d" viewBox="0 0 %d %d" role="img">`,
+		historyChartWidth, historyChartHeight, historyChartWidth, historyChartHeight)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#999"/>`,
+		left, top+plotHeight, left+plotWidth, top+plotHeight)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#999"/>`, left, top, left, top+plotHeight)
+	_, maxLabel := toMsString(max)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, left-4, top+10, strings.TrimSpace(maxLabel))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">0ms</text>`, left-4, top+plotHeight)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">runs, oldest to latest</text>`, left+4, historyChartHeight-4)
+
+	for i, m := range methods {
+		if len(m.History) == 0 {
+			continue
+		}
+
+		colour := historyColours[i%len(historyColours)]
+		points := make([]string, 0, len(m.History))
+		// Methods with fewer runs are aligned to the latest run.
+		offset := longest - len(m.History)
+		for j, point := range m.History {
+			x := left + plotWidth*(offset+j)/(longest-1)
+			if point.Status != framework.StatusOK {
+				fmt.Fprintf(&b, `<text x="%d" y="%d" fill="%s" text-anchor="middle">x</text>`, x, top+8, colour)
+				continue
+			}
+
+			y := top + plotHeight - int(float64(plotHeight)*float64(point.TimeCost)/float64(max))
+			points = append(points, fmt.Sprintf("%d,%d", x, y))
+			fmt.Fprintf(&b, `<circle cx="%d" cy="%d" r="2.5" fill="%s"><title>%s %s at %s</title></circle>`,
+				x, y, colour, template.HTMLEscapeString(m.Name), point.CommitLabel(),
+				point.StartedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`,
+			strings.Join(points, " "), colour)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" fill="%s">%s</text>`,
+			historyChartWidth-150, top+12+14*i, colour, template.HTMLEscapeString(m.Name))
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 960px; color: #222; }
+table { border-collapse: collapse; margin: 0.5em 0 1em; }
+th, td { border: 1px solid #ddd; padding: 4px 10px; text-align: left; }
+td.num { text-align: right; font-family: monospace; }
+svg { font-size: 12px; display: block; margin: 0.5em 0; }
+section { border-top: 1px solid #ccc; padding-top: 0.5em; }
+.correct { color: #2e9d4b; } .wrong { color: #d43c3c; } .unknown { color: #d9a21b; } .no-result { color: #8a8a8a; }
+.meta { color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Generated at {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}} on commit {{.Environment.CommitLabel}},
+{{.Environment.GoVersion}} {{.Environment.OS}}/{{.Environment.Arch}}, {{.Environment.CPUs}} CPUs.</p>
+<p>
+<span class="correct">{{index .Summary "correct"}} correct</span>,
+<span class="wrong">{{index .Summary "wrong"}} wrong</span>,
+<span class="unknown">{{index .Summary "unknown"}} unknown</span>,
+<span class="no-result">{{index .Summary "no-result"}} without result</span>
+</p>
+<table>
+<tr><th>Problem</th><th>Title</th><th>Method</th><th>Result</th><th>Status</th><th>Verdict</th><th>Time</th></tr>
+{{- range $p := .Problems}}{{range .Methods}}
+<tr><td><a href="#p{{$p.Id}}">{{$p.Id}}</a></td><td>{{$p.Title}}</td><td>{{.Name}}</td><td class="num">{{.Result}}</td>
+<td>{{if .Recorded}}{{.Status}}{{else}}not run{{end}}</td><td class="{{.Verdict}}">{{if .Recorded}}{{.Verdict}}{{end}}</td><td class="num">{{.Time}}</td></tr>
+{{- end}}{{end}}
+</table>
+{{range .Problems}}
+<section id="p{{.Id}}">
+<h2>{{.Id}}. {{.Title}}</h2>
+<p>Answer: {{.Answer}}</p>
+<table>
+<tr><th>Method</th><th>Result</th><th>Verdict</th><th>Time</th><th>Budget</th><th>Recorded at</th></tr>
+{{- range .Methods}}
+<tr><td>{{.Name}}{{if .Slow}} (slow){{end}}</td><td class="num">{{.Result}}</td><td class="{{.Verdict}}">{{if .Recorded}}{{.Verdict}}{{else}}not run{{end}}</td>
+<td class="num">{{.Time}}</td><td class="num">{{if .Budget}}{{.Budget}}{{end}}</td><td>{{if .Recorded}}{{.RecordedAt.Format "2006-01-02 15:04:05"}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{.BarChart}}
+{{if .History}}<h3>History</h3>
+{{.History}}{{end}}
+</section>
+{{end}}
+</body>
+</html>
+`))
+
+// doReport writes HTML report of recorded timings and history of selected problems.
+func doReport(conf *framework.Configure) {
+	timings, err := LoadTimings()
+	if err != nil {
+		os.Exit(errorExitCode(fmt.Errorf("load recorded timings failed: %w", err)))
+	}
+
+	runs, err := LoadHistory()
+	if err != nil {
+		os.Exit(errorExitCode(fmt.Errorf("load history failed: %w", err)))
+	}
+
+	selection, err := framework.ParseSelection(conf.Problems)
+	if err != nil {
+		os.Exit(errorExitCode(&UsageError{err}))
+	}
+
+	selected, err := selection.Resolve(problems.Problems, timings)
+	if err != nil {
+		os.Exit(errorExitCode(&UsageError{err}))
+	}
+
+	report := NewHTMLReport(selected, timings, runs)
+	out := os.Stdout
+	if conf.Output != "-" {
+		if out, err = os.Create(conf.Output); err != nil {
+			os.Exit(errorExitCode(err))
+		}
+	}
+
+	if err := htmlReportTemplate.Execute(out, report); err != nil {
+		os.Exit(errorExitCode(fmt.Errorf("write report failed: %w", err)))
+	}
+
+	if out != os.Stdout {
+		if err := out.Close(); err != nil {
+			os.Exit(errorExitCode(err))
+		}
+
+		fmt.Printf("report of %d problems written to %s\n", len(report.Problems), conf.Output)
+	}
+}
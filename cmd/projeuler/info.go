@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// descriptionWidth is the width description of problem is wrapped to.
+const descriptionWidth = 78
+
+func doList(conf *framework.Configure) {
+	for _, problem := range problems.Problems {
+		fmt.Printf("%-5d %-40s %s\n", problem.Id, rightPadding(problem.Title, 40, "."),
+			strings.Join(problem.MethodList(), ", "))
+	}
+}
+
+func doShow(conf *framework.Configure) {
+	if len(conf.Problems) == 0 {
+		fmt.Fprintf(os.Stderr, "projeuler: no problem given\n")
+		os.Exit(2)
+	}
+
+	timings, err := LoadTimings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: load recorded timings failed: %s\n", err)
+		timings = make(Timings)
+	}
+
+	selection, err := framework.ParseSelection(conf.Problems)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		os.Exit(2)
+	}
+
+	selected, err := selection.Resolve(problems.Problems, timings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		os.Exit(2)
+	}
+
+	for i, s := range selected {
+		info := NewProblemInfo(s.Problem, timings)
+		if conf.JSONOutput {
+			data, _ := json.Marshal(info)
+			fmt.Println(string(data))
+			continue
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+
+		info.Print()
+	}
+}
+
+// MethodInfo is a method of problem, with its latest recorded run if there is one.
+type MethodInfo struct {
+	Name       string                 `json:"name"`
+	Budget     time.Duration          `json:"budget_ns,omitempty"`
+	Slow       bool                   `json:"slow,omitempty"`
+	Status     framework.ResultStatus `json:"status,omitempty"`
+	TimeCost   *time.Duration         `json:"time_cost_ns,omitempty"`
+	RecordedAt *time.Time             `json:"recorded_at,omitempty"`
+}
+
+// ProblemInfo is everything known about a problem, printed by show command.
+type ProblemInfo struct {
+	Id          int          `json:"id"`
+	Title       string       `json:"title"`
+	Description []string     `json:"description"`
+	Tags        []string     `json:"tags,omitempty"`
+	AnswerKnown bool         `json:"answer_known"`
+	Answer      *int64       `json:"answer,omitempty"`
+	DataFile    string       `json:"data_file,omitempty"`
+	DataMissing bool         `json:"data_missing,omitempty"`
+	Methods     []MethodInfo `json:"methods"`
+}
+
+func NewProblemInfo(problem framework.Problem, timings Timings) *ProblemInfo {
+	info := &ProblemInfo{
+		Id:          problem.Id,
+		Title:       problem.Title,
+		Description: problem.Description,
+		Tags:        problem.Tags,
+		AnswerKnown: !problem.NoAnswer,
+		Methods:     make([]MethodInfo, 0, len(problem.Methods)),
+	}
+
+	if info.AnswerKnown {
+		answer := int64(problem.Answer)
+		info.Answer = &answer
+	}
+
+	if problem.DataFile != "" {
+		info.DataFile = framework.DataFilePath(problem.DataFile)
+		if _, err := os.Stat(info.DataFile); err != nil {
+			info.DataMissing = true
+		}
+	}
+
+	for _, method := range problem.MethodList() {
+		methodInfo := MethodInfo{
+			Name:   method,
+			Budget: problem.Budget(method),
+			Slow:   problem.IsSlow(method),
+		}
+
+		if record, found := timings.Get(problem.Id, method); found {
+			timeCost, recordedAt := record.TimeCost, record.RecordedAt
+			methodInfo.Status = record.Status
+			methodInfo.TimeCost = &timeCost
+			methodInfo.RecordedAt = &recordedAt
+		}
+
+		info.Methods = append(info.Methods, methodInfo)
+	}
+
+	return info
+}
+
+func (i *ProblemInfo) Print() {
+	fmt.Printf("Problem %d: %s\n\n", i.Id, i.Title)
+	for _, line := range wrapDescription(i.Description, descriptionWidth) {
+		fmt.Println(strings.TrimRight("  "+line, " "))
+	}
+
+	fmt.Println()
+	if i.Answer != nil {
+		fmt.Printf("Answer:  %d\n", *i.Answer)
+
+	} else {
+		fmt.Printf("Answer:  unknown\n")
+	}
+
+	if len(i.Tags) > 0 {
+		fmt.Printf("Tags:    %s\n", strings.Join(i.Tags, ", "))
+	}
+
+	if i.DataFile != "" {
+		missing := ""
+		if i.DataMissing {
+			missing = " (missing)"
+		}
+
+		fmt.Printf("Data:    %s%s\n", i.DataFile, missing)
+	}
+
+	fmt.Printf("Methods:\n")
+	for _, method := range i.Methods {
+		timing := "no recorded run"
+		if method.TimeCost != nil {
+			_, timeCost := toMsString(*method.TimeCost)
+			timing = fmt.Sprintf("%s %-12s at %s", timeCost, method.Status,
+				method.RecordedAt.Format("2006-01-02 15:04:05"))
+		}
+
+		budget := ""
+		if method.Budget > 0 {
+			budget = "budget " + method.Budget.String()
+		}
+
+		if method.Slow {
+			budget = strings.TrimLeft(budget+", slow", ", ")
+		}
+
+		fmt.Printf("  %-30s %-20s %s\n", method.Name, budget, timing)
+	}
+}
+
+// wrapDescription joins lines of description into paragraphs, and wraps them to width. Lines
+// starting with space are preformatted, like formulas and tables, they are kept as they are.
+func wrapDescription(lines []string, width int) []string {
+	result := make([]string, 0, len(lines))
+	words := make([]string, 0)
+	flush := func() {
+		current := ""
+		for _, word := range words {
+			if current != "" && len(current)+1+len(word) > width {
+				result = append(result, current)
+				current = ""
+			}
+
+			if current == "" {
+				current = word
+
+			} else {
+				current += " " + word
+			}
+		}
+
+		if current != "" {
+			result = append(result, current)
+		}
+
+		words = words[:0]
+	}
+
+	for _, line := range lines {
+		if line == "" || strings.HasPrefix(line, " ") {
+			flush()
+			result = append(result, line)
+			continue
+		}
+
+		words = append(words, strings.Fields(line)...)
+	}
+
+	flush()
+	return result
+}
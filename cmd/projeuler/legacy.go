@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// legacyMain runs projeuler with old style flags, like "projeuler -check 14". It is deprecated
+// by subcommands, and will be removed in the next release.
+func legacyMain(args []string) {
+	fs := flag.NewFlagSet("projeuler", flag.ExitOnError)
+	conf := &framework.Configure{}
+
+	fs.BoolVar(&conf.RunnerMode, "runner", true, "run in runner mode")
+	fs.BoolVar(&conf.CheckMode, "check", false, "check result")
+	failOnFlag(fs, conf)
+	fs.DurationVar(&conf.TotalTimeout, "total-timeout", 0, "total timeout, 0 means no timeout")
+	fs.DurationVar(&conf.ProblemTimeout, "problem-timeout", 5*time.Second, "problem timeout")
+	fs.DurationVar(&conf.MethodTimeout, "method-timeout", 500*time.Millisecond, "method timeout")
+	fs.DurationVar(&conf.ShutdownGrace, "shutdown-grace", time.Second, "grace period of worker shutdown")
+
+	fs.BoolVar(&conf.WorkerMode, "worker", false, "run in worker mode")
+	fs.BoolVar(&conf.StdioMode, "stdio", false, "serve worker on stdin and stdout")
+	fs.StringVar(&conf.Transport, "transport", framework.TransportStdio,
+		"transport between runner and worker, stdio, unix or tcp")
+	fs.IntVar(&conf.SpareWorkers, "spare-workers", 1, "number of spare workers started in advance")
+	fs.BoolVar(&conf.Isolate, "isolate", false, "run each method in a freshly started worker")
+	fs.Func("limit-memory", "memory limit of worker process, like 512M, 0 means no limit", func(s string) error {
+		size, err := framework.ParseByteSize(s)
+		conf.Limits.Memory = size
+		return err
+	})
+	fs.DurationVar(&conf.Limits.CPUTime, "limit-cpu", 0, "CPU time limit of each method, run in its own worker, 0 means no limit")
+	fs.IntVar(&conf.Limits.OpenFiles, "limit-files", 0, "open files limit of worker process, 0 means no limit")
+	fs.BoolVar(&conf.ClientMode, "client", false, "run in client mode")
+	fs.BoolVar(&conf.RawMode, "raw", false, "run in raw mode")
+	fs.IntVar(&conf.ServePort, "port", 1707,
+		"server port, 0 means any free port, runner uses TCP on a free port if it is given")
+	fs.StringVar(&conf.ServeSocket, "socket", "", "unix socket path of worker, override -port")
+	fs.IntVar(&conf.Concurrency, "concurrency", 1, "max number of runs in parallel in worker mode")
+	fs.StringVar(&conf.Codec, "codec", "", "message codec, binary or json, worker detects codec by default")
+	fs.IntVar(&conf.ReadyFd, "ready-fd", 0, "file descriptor to report worker address on when ready")
+	fs.DurationVar(&conf.ReadyTimeout, "ready-timeout", 5*time.Second, "time to wait a worker to be ready")
+	fs.BoolVar(&conf.DebugMode, "debug", false, "debug mode")
+	configFlag(fs, conf)
+
+	_ = fs.Parse(args)
+	if err := applyProjectConfig(fs, conf); err != nil {
+		fmt.Fprintf(os.Stderr, "projeuler: %s\n", err)
+		os.Exit(ExitUsage)
+	}
+
+	conf.Problems = fs.Args()
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "port" {
+			conf.Transport = framework.TransportTCP
+		}
+	})
+
+	initLogger(conf)
+	printDeprecation(conf)
+
+	if conf.WorkerMode {
+		runWorker(conf)
+
+	} else if conf.ClientMode {
+		doClient(conf)
+
+	} else if conf.RawMode {
+		doRunRaw(conf)
+
+	} else if conf.RunnerMode {
+		report, err := runProblems(conf, problems.Problems)
+		exitRun(conf, report, err)
+
+	} else {
+		fs.Usage()
+	}
+}
+
+// legacyCommand returns the subcommand replacing old style flags.
+func legacyCommand(conf *framework.Configure) string {
+	switch {
+	case conf.WorkerMode:
+		return "worker"
+
+	case conf.ClientMode:
+		return "client"
+
+	case conf.RawMode:
+		return "run -raw"
+
+	case conf.CheckMode:
+		return "check"
+
+	default:
+		return "run"
+	}
+}
+
+func printDeprecation(conf *framework.Configure) {
+	// Worker spawned by an old runner must not print anything but messages.
+	if conf.WorkerMode {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "projeuler: running without command is deprecated and will be removed in "+
+		"the next release, use 'projeuler %s' instead\n", legacyCommand(conf))
+}
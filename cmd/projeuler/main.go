@@ -1,60 +1,127 @@
 package main
 
 import (
-	"flag"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/message"
 	"github.com/flily/projeuler.go/framework/problems"
 )
 
+func newWorker(conf *framework.Configure) (*framework.Worker, error) {
+	if !conf.StdioMode {
+		return framework.NewWorker(conf.ServeAddress())
+	}
+
+	// Messages are sent through stdout, so output of solutions MUST go somewhere else.
+	stdout, err := redirectStdout()
+	if err != nil {
+		return nil, fmt.Errorf("redirect stdout failed: %w", err)
+	}
+
+	return framework.NewPipeWorker(os.Stdin, stdout), nil
+}
+
 func runWorker(conf *framework.Configure) {
-	worker, err := framework.NewWorker("127.0.0.1", conf.ServePort)
+	if err := conf.Limits.Apply(); err != nil {
+		fmt.Printf("apply resource limits failed: %s\n", err)
+		os.Exit(framework.WorkerExitError)
+		return
+	}
+
+	worker, err := newWorker(conf)
 	if err != nil {
 		fmt.Printf("start worker failed: %s\n", err)
-		os.Exit(1)
+		os.Exit(framework.WorkerExitError)
 		return
 	}
 
+	if conf.Codec != "" {
+		codec, err := message.NewCodec(conf.Codec)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			os.Exit(framework.WorkerExitError)
+			return
+		}
+
+		worker.SetCodec(codec)
+	}
+
+	if conf.ReadyFd > 0 {
+		ready := os.NewFile(uintptr(conf.ReadyFd), "ready")
+		err := framework.WriteReady(ready, worker.Address)
+		_ = ready.Close()
+		if err != nil {
+			fmt.Printf("report readiness failed: %s\n", err)
+			os.Exit(framework.WorkerExitError)
+			return
+		}
+	}
+
+	worker.SetConcurrency(conf.Concurrency)
 	worker.Import(problems.Problems)
+	go handleWorkerSignals(conf, worker)
 	go worker.Serve()
-	worker.Process()
+	os.Exit(worker.Process())
+}
+
+// handleWorkerSignals shuts down worker gracefully on the first SIGTERM or SIGINT, and exits
+// immediately on the second one.
+func handleWorkerSignals(conf *framework.Configure, worker *framework.Worker) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	watchWorkerSignals(signals, conf.ShutdownGrace, worker, os.Exit)
+}
+
+func watchWorkerSignals(signals <-chan os.Signal, grace time.Duration, worker *framework.Worker,
+	exit func(code int)) {
+	sig := <-signals
+	log.Printf("received %s, shutdown in %s", sig, grace)
+	worker.Shutdown(framework.WorkerExitOK, grace)
+
+	sig = <-signals
+	log.Printf("received %s again, exit now", sig)
+	exit(framework.WorkerExitCanceled)
 }
 
+// doClient runs selected methods on a worker already started, methods failed to run are
+// reported and the others still run.
 func doClient(conf *framework.Configure) {
-	client, err := framework.NewClient("127.0.0.1", conf.ServePort)
+	codec, err := message.NewCodec(conf.Codec)
 	if err != nil {
-		fmt.Printf("ERROR: %s\n", err)
-		return
+		os.Exit(errorExitCode(&UsageError{err}))
 	}
 
-	for _, problem := range conf.Problems {
-		info, err := framework.ParseProblemId(problem)
-		if err != nil {
-			fmt.Printf("ERROR: %s\n", err)
-			continue
-		}
+	selected, err := selectProblems(conf, problems.Problems)
+	if err != nil {
+		os.Exit(errorExitCode(&UsageError{err}))
+	}
 
-		methods := make([]string, 0, 1)
-		problem, found := problems.GetProblem(info.ProblemId)
-		if found && info.Method == "" {
-			for method := range problem.Methods {
-				methods = append(methods, method)
-			}
+	client, err := framework.NewClient(conf.ServeAddress())
+	if err != nil {
+		os.Exit(errorExitCode(err))
+	}
 
-		} else {
-			methods = append(methods, info.Method)
-		}
+	client.SetCodec(codec)
+	failed := 0
+	for _, selection := range selected {
+		fmt.Printf("run problem %d\n", selection.Problem.Id)
+		for _, method := range selection.Methods {
+			result, err := client.Run(selection.Problem.Id, method)
+			if err == nil && len(result.Results) == 0 && result.Message != "" {
+				err = errors.New(result.Message)
+			}
 
-		fmt.Printf("run problem %d\n", info.ProblemId)
-		for _, method := range methods {
-			result, err := client.Run(info.ProblemId, method)
 			if err != nil {
-				fmt.Printf("ERROR: %s\n", err)
+				fmt.Fprintf(os.Stderr, "ERROR: run %d '%s' failed: %s\n", selection.Problem.Id, method, err)
+				failed++
 				continue
 			}
 
@@ -63,6 +130,10 @@ func doClient(conf *framework.Configure) {
 			}
 		}
 	}
+
+	if failed > 0 {
+		os.Exit(ExitCrash)
+	}
 }
 
 func doRunRaw(conf *framework.Configure) {
@@ -72,24 +143,19 @@ func doRunRaw(conf *framework.Configure) {
 	runner := framework.NewRunner()
 	runner.Import(problems.Problems)
 
-	infoList, err := framework.ParseProblemIdList(conf.Problems)
+	selected, err := selectProblems(conf, problems.Problems)
 	if err != nil {
-		fmt.Printf("ERROR: %s\n", err)
-		return
+		os.Exit(errorExitCode(&UsageError{err}))
 	}
 
-	var results []*framework.Result
-
-	if len(infoList) > 0 {
-		results, err = runner.RunProblemsWithTimeout(ctx, infoList)
-
-	} else {
-		results, err = runner.RunAllProblemsWithTimeout(ctx)
+	infoList := make([]framework.ProblemRunInfo, 0, len(selected))
+	for _, selection := range selected {
+		infoList = append(infoList, selection.RunInfoList()...)
 	}
 
+	results, err := runner.RunProblemsWithTimeout(ctx, infoList)
 	if err != nil {
-		log.Printf("run problem solution error: %s\n", err)
-		return
+		os.Exit(errorExitCode(fmt.Errorf("run problem solution error: %w", err)))
 	}
 
 	for _, result := range results {
@@ -108,40 +174,25 @@ func initLogger(conf *framework.Configure) {
 }
 
 func main() {
-	conf := &framework.Configure{}
-
-	flag.BoolVar(&conf.RunnerMode, "runner", true, "run in runner mode")
-	flag.BoolVar(&conf.CheckMode, "check", false, "check result")
-	flag.DurationVar(&conf.TotalTimeout, "total-timeout", 0, "total timeout, 0 means no timeout")
-	flag.DurationVar(&conf.ProblemTimeout, "problem-timeout", 5*time.Second, "problem timeout")
-	flag.DurationVar(&conf.MethodTimeout, "method-timeout", 500*time.Millisecond, "method timeout")
-
-	flag.BoolVar(&conf.WorkerMode, "worker", false, "run in worker mode")
-	flag.BoolVar(&conf.ClientMode, "client", false, "run in client mode")
-	flag.BoolVar(&conf.RawMode, "raw", false, "run in raw mode")
-	flag.IntVar(&conf.ServePort, "port", 1707, "server port")
-	flag.BoolVar(&conf.DebugMode, "debug", false, "debug mode")
-
-	flag.Parse()
-
-	conf.Problems = flag.Args()
-	conf.RunPort = conf.ServePort
-
-	initLogger(conf)
-
-	if conf.WorkerMode {
-		runWorker(conf)
-
-	} else if conf.ClientMode {
-		doClient(conf)
-
-	} else if conf.RawMode {
-		doRunRaw(conf)
+	// Without command, all problems are run as before subcommands, with a deprecation notice.
+	args := os.Args[1:]
+	if len(args) == 0 || isLegacyArgs(args) {
+		legacyMain(args)
+		return
+	}
 
-	} else if conf.RunnerMode {
-		runProblems(conf, problems.Problems)
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		doHelp(args[1:])
+		return
+	}
 
-	} else {
-		flag.Usage()
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "projeuler: unknown command '%s'\n\n", args[0])
+		printUsage()
+		os.Exit(2)
 	}
+
+	cmd.Execute(args[1:])
 }
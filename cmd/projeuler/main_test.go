@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// testWorkerProblems are served by the test binary, when it is started as a worker by tests
+// running methods through worker processes.
+var testWorkerProblems = []framework.Problem{{
+	Id:       1,
+	NoAnswer: true,
+	Methods: map[string]framework.Solution{
+		"burn-a": func() int64 { return burnCPU(600 * time.Millisecond) },
+		"burn-b": func() int64 { return burnCPU(600 * time.Millisecond) },
+	},
+}, {
+	Id:     2,
+	Answer: 42,
+	Methods: map[string]framework.Solution{
+		"write-fd": func() int64 {
+			_, _ = syscall.Write(1, []byte("stray output to fd 1\n"))
+			return 42
+		},
+		"write-captured": func() int64 {
+			fmt.Fprintln(capturedStdout, "stray output to stdout captured before")
+			return 42
+		},
+		"write-color": func() int64 {
+			color.New(color.FgRed).Fprintln(color.Output, "stray output in color")
+			return 42
+		},
+	},
+}}
+
+// capturedStdout is stdout got before worker starts, like writers of other packages keep.
+var capturedStdout io.Writer = os.Stdout
+
+// burnCPU keeps a CPU busy for d.
+func burnCPU(d time.Duration) int64 {
+	n := int64(0)
+	for start := time.Now(); time.Since(start) < d; {
+		n++
+	}
+
+	return n
+}
+
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		problems.Problems = testWorkerProblems
+		main()
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestStdioWorkerRedirectsStdout(t *testing.T) {
+	stdinR, stdinW, _ := os.Pipe()
+	stdoutR, stdoutW, _ := os.Pipe()
+	stderrR, stderrW, _ := os.Pipe()
+	savedStdin, savedStdout, savedStderr := os.Stdin, os.Stdout, os.Stderr
+	defer func() {
+		os.Stdin, os.Stdout, os.Stderr = savedStdin, savedStdout, savedStderr
+	}()
+
+	os.Stdin, os.Stdout, os.Stderr = stdinR, stdoutW, stderrW
+	worker, err := newWorker(&framework.Configure{StdioMode: true})
+	if err != nil {
+		t.Fatalf("create stdio worker failed: %s", err)
+	}
+
+	worker.Import([]framework.Problem{{
+		Id:     1,
+		Answer: 42,
+		Methods: map[string]framework.Solution{
+			"noisy": func() int64 {
+				fmt.Println("stray output of solution")
+				return 42
+			},
+		},
+	}})
+
+	exitCode := make(chan int, 1)
+	go worker.Serve()
+	go func() { exitCode <- worker.Process() }()
+
+	client := framework.NewPipeClient(stdoutR, stdinW)
+	result, err := client.Run(1, "noisy")
+	if err != nil {
+		t.Fatalf("run through stdio failed, protocol stream corrupted: %s", err)
+	}
+
+	if len(result.Results) != 1 || result.Results[0].Result != 42 {
+		t.Errorf("unexpected result %+v", result.Results)
+	}
+
+	client.Close()
+	if code := <-exitCode; code != framework.WorkerExitOK {
+		t.Errorf("expected worker exit %d after pipe closed, got %d", framework.WorkerExitOK, code)
+	}
+
+	// Stdout may be a duplicate of stderr now, both are closed to read stderr to the end.
+	_ = stdoutW.Close()
+	_ = stderrW.Close()
+	output, _ := io.ReadAll(stderrR)
+	if !strings.Contains(string(output), "stray output of solution") {
+		t.Errorf("output of solution should go to stderr, got %q", output)
+	}
+}
+
+func TestWorkerSignals(t *testing.T) {
+	worker, err := framework.NewWorker("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("start worker failed: %s", err)
+	}
+
+	exitCode := make(chan int, 1)
+	go worker.Serve()
+	go func() { exitCode <- worker.Process() }()
+
+	signals := make(chan os.Signal, 2)
+	exited := make(chan int, 1)
+	go watchWorkerSignals(signals, time.Second, worker, func(code int) { exited <- code })
+
+	// The first signal shuts down worker gracefully, an idle worker exits at once.
+	signals <- os.Interrupt
+	select {
+	case code := <-exitCode:
+		if code != framework.WorkerExitOK {
+			t.Errorf("expected exit status %d on first signal, got %d", framework.WorkerExitOK, code)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatalf("worker does not shut down on first signal")
+	}
+
+	signals <- syscall.SIGTERM
+	if code := <-exited; code != framework.WorkerExitCanceled {
+		t.Errorf("expected exit status %d on second signal, got %d", framework.WorkerExitCanceled, code)
+	}
+}
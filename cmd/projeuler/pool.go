@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+// pooledWorker is a started worker process with a connected client.
+type pooledWorker struct {
+	proc   *framework.WorkerProc
+	client *framework.Client
+	runs   int
+}
+
+func (w *pooledWorker) stop(grace time.Duration) {
+	w.proc.Stop(w.client, grace)
+	w.client.Close()
+}
+
+type spawnResult struct {
+	worker *pooledWorker
+	err    error
+}
+
+type PoolStats struct {
+	Restarts     int
+	Spawns       int
+	SpawnTotal   time.Duration
+	SpawnMin     time.Duration
+	SpawnMax     time.Duration
+	WaitForSpare time.Duration
+}
+
+func (s PoolStats) SpawnAverage() time.Duration {
+	if s.Spawns <= 0 {
+		return 0
+	}
+
+	return s.SpawnTotal / time.Duration(s.Spawns)
+}
+
+// WorkerPool keeps an active worker for running methods, and some spare workers already started
+// and connected. When the active worker has to be replaced, a spare one is swapped in at once,
+// and a new spare worker is started in background.
+type WorkerPool struct {
+	conf      *framework.Configure
+	socketDir string
+	active    *pooledWorker
+	spares    chan spawnResult
+	retiring  sync.WaitGroup
+
+	lock     sync.Mutex
+	sequence int
+	stats    PoolStats
+}
+
+func NewWorkerPool(conf *framework.Configure, spares int) (*WorkerPool, error) {
+	if spares < 0 {
+		spares = 0
+	}
+
+	p := &WorkerPool{
+		conf:   conf,
+		spares: make(chan spawnResult, spares),
+	}
+
+	if conf.Transport == framework.TransportUnix {
+		dir, err := os.MkdirTemp("", "projeuler-")
+		if err != nil {
+			return nil, err
+		}
+
+		p.socketDir = dir
+	}
+
+	active, err := p.spawn(conf.Limits)
+	if err != nil {
+		p.removeSocketDir()
+		return nil, err
+	}
+
+	p.active = active
+	for i := 0; i < spares; i++ {
+		go p.refill()
+	}
+
+	return p, nil
+}
+
+// spawn starts a worker with its own copy of configure, so workers can be started in parallel.
+func (p *WorkerPool) spawn(limits framework.ResourceLimits) (*pooledWorker, error) {
+	p.lock.Lock()
+	p.sequence++
+	sequence := p.sequence
+	p.lock.Unlock()
+
+	conf := *p.conf
+	conf.Limits = limits
+	if p.socketDir != "" {
+		conf.RunSocket = filepath.Join(p.socketDir, fmt.Sprintf("worker-%d.sock", sequence))
+	}
+
+	start := time.Now()
+	proc, client, err := initConnection(&conf)
+	if err != nil {
+		return nil, err
+	}
+
+	cost := time.Since(start)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.stats.Spawns++
+	p.stats.SpawnTotal += cost
+	if p.stats.SpawnMin == 0 || cost < p.stats.SpawnMin {
+		p.stats.SpawnMin = cost
+	}
+
+	if cost > p.stats.SpawnMax {
+		p.stats.SpawnMax = cost
+	}
+
+	w := &pooledWorker{
+		proc:   proc,
+		client: client,
+	}
+
+	return w, nil
+}
+
+// refill starts a spare worker with default limits.
+func (p *WorkerPool) refill() {
+	worker, err := p.spawn(p.conf.Limits)
+	p.spares <- spawnResult{
+		worker: worker,
+		err:    err,
+	}
+}
+
+// Run runs a method on the active worker, with timeouts in settings.
+func (p *WorkerPool) Run(problemId int, method string, settings framework.MethodSettings) (*framework.Result, error) {
+	p.active.runs++
+	return p.active.client.RunWithTimeout(problemId, method, settings.ProblemTimeout, settings.MethodTimeout)
+}
+
+// PerMethod tells whether each method must run on a fresh worker. CPU time limit counts the
+// whole lifetime of worker process, so it limits a method only if worker runs nothing else.
+func (p *WorkerPool) PerMethod() bool {
+	return p.active.proc.Limits.CPUTime > 0
+}
+
+// Fresh tells whether the active worker has not run any method yet.
+func (p *WorkerPool) Fresh() bool {
+	return p.active.runs == 0
+}
+
+func (p *WorkerPool) Worker() *framework.WorkerProc {
+	return p.active.proc
+}
+
+// Use makes sure the active worker runs with given resource limits, and replaces it if not.
+func (p *WorkerPool) Use(limits framework.ResourceLimits) error {
+	if p.active.proc.Limits == limits {
+		return nil
+	}
+
+	return p.replaceWith(limits)
+}
+
+// Replace retires the active worker in background, and swaps in a spare worker. It only blocks
+// if no spare worker is ready yet.
+func (p *WorkerPool) Replace() error {
+	return p.replaceWith(p.active.proc.Limits)
+}
+
+// replaceWith swaps in a new worker with given limits. Spare workers are started with default
+// limits, so a worker with any other limits is started on demand.
+func (p *WorkerPool) replaceWith(limits framework.ResourceLimits) error {
+	old := p.active
+	p.active = nil
+	p.retiring.Add(1)
+	go func() {
+		defer p.retiring.Done()
+		old.stop(p.conf.ShutdownGrace)
+	}()
+
+	p.lock.Lock()
+	p.stats.Restarts++
+	p.lock.Unlock()
+
+	if cap(p.spares) <= 0 || limits != p.conf.Limits {
+		worker, err := p.spawn(limits)
+		if err != nil {
+			return err
+		}
+
+		p.active = worker
+		return nil
+	}
+
+	start := time.Now()
+	spare := <-p.spares
+	wait := time.Since(start)
+	go p.refill()
+
+	p.lock.Lock()
+	p.stats.WaitForSpare += wait
+	p.lock.Unlock()
+
+	if spare.err != nil {
+		return spare.err
+	}
+
+	p.active = spare.worker
+	return nil
+}
+
+// Close stops all workers, including spare workers being started.
+func (p *WorkerPool) Close() {
+	if p.active != nil {
+		p.active.stop(p.conf.ShutdownGrace)
+		p.active = nil
+	}
+
+	for i := 0; i < cap(p.spares); i++ {
+		spare := <-p.spares
+		if spare.err == nil {
+			spare.worker.stop(p.conf.ShutdownGrace)
+		}
+	}
+
+	p.retiring.Wait()
+	p.removeSocketDir()
+}
+
+func (p *WorkerPool) removeSocketDir() {
+	if p.socketDir != "" {
+		_ = os.RemoveAll(p.socketDir)
+	}
+}
+
+func (p *WorkerPool) Stats() PoolStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.stats
+}
+
+func printPoolStats(stats PoolStats) {
+	_, spawnAvg := toMsString(stats.SpawnAverage())
+	_, spawnMax := toMsString(stats.SpawnMax)
+	_, wait := toMsString(stats.WaitForSpare)
+	fmt.Printf("worker pool: %d restarts, %d spawns, spawn latency avg %s max %s, waited %s for spare\n",
+		stats.Restarts, stats.Spawns,
+		strings.TrimSpace(spawnAvg), strings.TrimSpace(spawnMax), strings.TrimSpace(wait))
+}
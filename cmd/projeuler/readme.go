@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flily/projeuler.go/framework"
+	"github.com/flily/projeuler.go/framework/problems"
+)
+
+// Progress section of README is between these lines, everything between them is replaced.
+const (
+	progressBegin = "<!-- projeuler:progress:begin (generated by 'projeuler readme', do not edit) -->"
+	progressEnd   = "<!-- projeuler:progress:end -->"
+)
+
+// Answer verification status of a problem in progress table.
+const (
+	AnswerVerified   = "verified"
+	AnswerWrong      = "wrong"
+	AnswerUnverified = "unverified"
+	AnswerUnknown    = "unknown"
+)
+
+// ProgressRow is a problem in progress table of README.
+type ProgressRow struct {
+	Id       int
+	Title    string
+	Methods  int
+	Answer   string
+	BestTime string
+}
+
+// newProgressRow makes row of problem from recorded runs of its methods. It tells whether any
+// method of problem is recorded, the answer and best time are not known otherwise.
+func newProgressRow(problem framework.Problem, timings Timings) (ProgressRow, bool) {
+	row := ProgressRow{
+		Id:       problem.Id,
+		Title:    problem.Title,
+		Methods:  len(problem.Methods),
+		Answer:   AnswerUnverified,
+		BestTime: "-",
+	}
+
+	if problem.NoAnswer {
+		row.Answer = AnswerUnknown
+	}
+
+	recorded := false
+	best := -1.0
+	for _, method := range problem.MethodList() {
+		record, found := timings.Get(problem.Id, method)
+		if !found {
+			continue
+		}
+
+		recorded = true
+		verdict := problem.Verdict(framework.ResultItem{Status: record.Status, Result: record.Result})
+		switch verdict {
+		case framework.VerdictCorrect:
+			row.Answer = AnswerVerified
+
+		case framework.VerdictWrong:
+			if row.Answer != AnswerVerified {
+				row.Answer = AnswerWrong
+			}
+
+			continue
+
+		case framework.VerdictNoResult:
+			continue
+		}
+
+		if ms, _ := toMsString(record.TimeCost); best < 0 || ms < best {
+			best = ms
+		}
+	}
+
+	if best >= 0 {
+		row.BestTime = fmt.Sprintf("%.3f ms", best)
+	}
+
+	return row, recorded
+}
+
+func (r ProgressRow) String() string {
+	title := strings.ReplaceAll(r.Title, "|", `\|`)
+	return fmt.Sprintf("| %d | %s | %d | %s | %s |", r.Id, title, r.Methods, r.Answer, r.BestTime)
+}
+
+// parseProgressRows reads rows in progress section, indexed by problem id. Only answer and best
+// time are read, which are kept for problems not recorded.
+func parseProgressRows(section string) map[int]ProgressRow {
+	rows := make(map[int]ProgressRow)
+	for _, line := range strings.Split(section, "\n") {
+		cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+		if len(cells) < 5 {
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(cells[0]))
+		if err != nil {
+			continue
+		}
+
+		rows[id] = ProgressRow{
+			Id:       id,
+			Answer:   strings.TrimSpace(cells[len(cells)-2]),
+			BestTime: strings.TrimSpace(cells[len(cells)-1]),
+		}
+	}
+
+	return rows
+}
+
+// ProgressSection renders progress table of problems, with summary counts. Answer and best time
+// of problems not in timings are kept from rows of the previous section.
+func ProgressSection(all []framework.Problem, timings Timings, previous map[int]ProgressRow) string {
+	lines := []string{
+		progressBegin,
+		"| Problem | Title | Methods | Answer | Best time |",
+		"|--------:|-------|--------:|--------|----------:|",
+	}
+
+	methods := 0
+	answers := make(map[string]int)
+	for _, problem := range all {
+		row, recorded := newProgressRow(problem, timings)
+		if old, found := previous[problem.Id]; found && !recorded {
+			row.Answer, row.BestTime = old.Answer, old.BestTime
+		}
+
+		methods += row.Methods
+		answers[row.Answer]++
+		lines = append(lines, row.String())
+	}
+
+	lines = append(lines, "",
+		fmt.Sprintf("%d problems, %d methods: %d verified, %d wrong, %d unverified, %d with unknown answer.",
+			len(all), methods, answers[AnswerVerified], answers[AnswerWrong], answers[AnswerUnverified],
+			answers[AnswerUnknown]),
+		progressEnd)
+
+	return strings.Join(lines, "\n")
+}
+
+// findProgressSection returns the start and end offsets of progress section in content, both
+// marker lines included.
+func findProgressSection(content string) (int, int, error) {
+	begin := strings.Index(content, progressBegin)
+	end := strings.Index(content, progressEnd)
+	if begin < 0 || end < begin {
+		return 0, 0, fmt.Errorf("no progress section found, mark where it goes with lines\n    %s\n    %s",
+			progressBegin, progressEnd)
+	}
+
+	return begin, end + len(progressEnd), nil
+}
+
+func readmeFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.StringVar(&conf.ReadmeFile, "file", "README.md", "markdown file with progress section")
+	fs.BoolVar(&conf.ReadmeCheck, "check", false,
+		"do not write, fail if progress section is out of date with problems")
+}
+
+// doReadme regenerates progress section of README from problems and recorded timings. With
+// -check, recorded timings are not read, since they differ between machines, only problems,
+// titles and methods are checked.
+func doReadme(conf *framework.Configure) {
+	data, err := os.ReadFile(conf.ReadmeFile)
+	if errors.Is(err, os.ErrNotExist) {
+		os.Exit(errorExitCode(&UsageError{err}))
+
+	} else if err != nil {
+		os.Exit(errorExitCode(err))
+	}
+
+	content := string(data)
+	begin, end, err := findProgressSection(content)
+	if err != nil {
+		os.Exit(errorExitCode(&UsageError{fmt.Errorf("%s: %w", conf.ReadmeFile, err)}))
+	}
+
+	timings := make(Timings)
+	if !conf.ReadmeCheck {
+		if timings, err = LoadTimings(); err != nil {
+			os.Exit(errorExitCode(fmt.Errorf("load recorded timings failed: %w", err)))
+		}
+	}
+
+	section := content[begin:end]
+	generated := ProgressSection(problems.Problems, timings, parseProgressRows(section))
+	if generated == section {
+		fmt.Printf("%s is up to date\n", conf.ReadmeFile)
+		return
+	}
+
+	if conf.ReadmeCheck {
+		fmt.Printf("%s is out of date, run 'projeuler readme' to update\n", conf.ReadmeFile)
+		printLineDiff(section, generated)
+		os.Exit(ExitWrong)
+	}
+
+	content = content[:begin] + generated + content[end:]
+	if err := os.WriteFile(conf.ReadmeFile, []byte(content), 0o644); err != nil {
+		os.Exit(errorExitCode(err))
+	}
+
+	fmt.Printf("%s updated, %d problems\n", conf.ReadmeFile, len(problems.Problems))
+}
+
+// printLineDiff prints lines removed from old and added in new.
+func printLineDiff(old, new string) {
+	oldLines := make(map[string]bool)
+	for _, line := range strings.Split(old, "\n") {
+		oldLines[line] = true
+	}
+
+	newLines := make(map[string]bool)
+	for _, line := range strings.Split(new, "\n") {
+		newLines[line] = true
+	}
+
+	for _, line := range strings.Split(old, "\n") {
+		if !newLines[line] {
+			fmt.Printf("- %s\n", line)
+		}
+	}
+
+	for _, line := range strings.Split(new, "\n") {
+		if !oldLines[line] {
+			fmt.Printf("+ %s\n", line)
+		}
+	}
+}
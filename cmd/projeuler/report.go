@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+// Output formats of runs.
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+	FormatJUnit    = "junit"
+)
+
+// RunRecord is result of running a method, with everything written in machine-readable formats.
+type RunRecord struct {
+	Problem  int                    `json:"problem"`
+	Title    string                 `json:"title"`
+	Method   string                 `json:"method"`
+	Result   *int64                 `json:"result"`
+	Expected *int64                 `json:"expected,omitempty"`
+	Status   framework.ResultStatus `json:"status"`
+	TimeCost time.Duration          `json:"time_ns"`
+	Verdict  framework.Verdict      `json:"verdict"`
+	Message  string                 `json:"message,omitempty"`
+}
+
+func (r *RunRecord) ResultString() string {
+	if r.Result == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(*r.Result, 10)
+}
+
+func (r *RunRecord) TimeMs() string {
+	ms, _ := toMsString(r.TimeCost)
+	return strconv.FormatFloat(ms, 'f', 3, 64)
+}
+
+// Report collects results of all methods run.
+type Report struct {
+	Records []RunRecord
+}
+
+func (r *Report) Add(problem framework.Problem, result *framework.Result) {
+	for _, item := range result.Results {
+		record := RunRecord{
+			Problem:  problem.Id,
+			Title:    problem.Title,
+			Method:   item.Method,
+			Status:   item.Status,
+			TimeCost: item.TimeCost,
+			Verdict:  problem.Verdict(item),
+			Message:  item.ExitStatus,
+		}
+
+		if item.HasResult() {
+			value := item.Result
+			record.Result = &value
+		}
+
+		if !problem.NoAnswer {
+			expected := int64(problem.Answer)
+			record.Expected = &expected
+		}
+
+		r.Records = append(r.Records, record)
+	}
+}
+
+// ReportWriter writes report in a format.
+type ReportWriter func(w io.Writer, report *Report) error
+
+var reportWriters = map[string]ReportWriter{
+	FormatJSON:     writeJSONReport,
+	FormatCSV:      writeCSVReport,
+	FormatMarkdown: writeMarkdownReport,
+	FormatJUnit:    writeJUnitReport,
+}
+
+// checkFormat tells whether format is supported.
+func checkFormat(format string) error {
+	if _, found := reportWriters[format]; found || format == FormatTable || format == "" {
+		return nil
+	}
+
+	return fmt.Errorf("unknown format '%s', table, json, csv, markdown or junit expected", format)
+}
+
+// printsTable tells whether the human table is printed on terminal, it is replaced by the
+// report if the report is written to stdout.
+func printsTable(conf *framework.Configure) bool {
+	return conf.Format == FormatTable || conf.Format == "" || conf.Output != ""
+}
+
+// WriteReport writes report in format of configure, to output file or stdout.
+func WriteReport(conf *framework.Configure, report *Report) error {
+	write, found := reportWriters[conf.Format]
+	if !found {
+		return nil
+	}
+
+	if conf.Output == "" {
+		return write(os.Stdout, report)
+	}
+
+	file, err := os.Create(conf.Output)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file, report); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func writeJSONReport(w io.Writer, report *Report) error {
+	records := report.Records
+	if records == nil {
+		records = []RunRecord{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+func writeCSVReport(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"problem", "title", "method", "result", "status", "time_ms", "verdict"})
+	for _, r := range report.Records {
+		_ = writer.Write([]string{
+			strconv.Itoa(r.Problem), r.Title, r.Method, r.ResultString(),
+			string(r.Status), r.TimeMs(), string(r.Verdict),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeMarkdownReport(w io.Writer, report *Report) error {
+	escape := strings.NewReplacer("|", `\|`)
+	lines := []string{
+		"| Problem | Title | Method | Result | Status | Time (ms) | Verdict |",
+		"|--------:|-------|--------|-------:|--------|----------:|---------|",
+	}
+
+	for _, r := range report.Records {
+		lines = append(lines, fmt.Sprintf("| %d | %s | %s | %s | %s | %s | %s |",
+			r.Problem, escape.Replace(r.Title), escape.Replace(r.Method), r.ResultString(),
+			r.Status, r.TimeMs(), r.Verdict))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+}
+
+// writeJUnitReport writes each problem as a test suite, and each method as a test case. Wrong
+// answers and timeouts are failures, crashes and resource limits are errors.
+func writeJUnitReport(w io.Writer, report *Report) error {
+	suites := junitTestSuites{}
+	var suite *junitTestSuite
+	var suiteTime time.Duration
+	for _, r := range report.Records {
+		name := fmt.Sprintf("p%04d", r.Problem)
+		if suite == nil || suite.Name != name+" "+r.Title {
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: name + " " + r.Title})
+			suite = &suites.Suites[len(suites.Suites)-1]
+			suiteTime = 0
+		}
+
+		testCase := junitTestCase{
+			ClassName: name,
+			Name:      r.Method,
+			Time:      junitSeconds(r.TimeCost),
+		}
+
+		switch {
+		case r.Verdict == framework.VerdictWrong:
+			testCase.Failure = &junitFailure{
+				Type:    string(r.Verdict),
+				Message: fmt.Sprintf("got %s, expected %d", r.ResultString(), *r.Expected),
+			}
+
+		case r.Status == framework.StatusTimeout:
+			testCase.Failure = &junitFailure{Type: string(r.Status), Message: "method timed out"}
+
+		case r.Verdict == framework.VerdictNoResult:
+			message := r.Message
+			if message == "" {
+				message = string(r.Status)
+			}
+
+			testCase.Error = &junitFailure{Type: string(r.Status), Message: message}
+
+		case r.Verdict == framework.VerdictUnknown:
+			testCase.SystemOut = fmt.Sprintf("result %s, answer unknown", r.ResultString())
+		}
+
+		suite.Tests++
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+
+		if testCase.Error != nil {
+			suite.Errors++
+		}
+
+		suiteTime += r.TimeCost
+		suite.Time = junitSeconds(suiteTime)
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
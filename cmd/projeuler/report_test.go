@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func makeTestReport() *Report {
+	return &Report{Records: []RunRecord{
+		{
+			Problem: 14, Title: "Longest Collatz sequence", Method: "naive",
+			Result: int64Ptr(837799), Expected: int64Ptr(837799),
+			Status: framework.StatusOK, TimeCost: 558397 * time.Microsecond, Verdict: framework.VerdictCorrect,
+		},
+		{
+			Problem: 14, Title: "Longest Collatz sequence", Method: `a<b & "c", d`,
+			Result: int64Ptr(1), Expected: int64Ptr(837799),
+			Status: framework.StatusOK, TimeCost: 1500 * time.Microsecond, Verdict: framework.VerdictWrong,
+		},
+		{
+			Problem: 23, Title: `Non-abundant sums | "<&>", x`, Method: "naive",
+			Expected: int64Ptr(4179871),
+			Status:   framework.StatusTimeout, TimeCost: time.Second, Verdict: framework.VerdictNoResult,
+		},
+		{
+			Problem: 23, Title: `Non-abundant sums | "<&>", x`, Method: "crash",
+			Expected: int64Ptr(4179871),
+			Status:   framework.StatusCrashed, TimeCost: 2 * time.Millisecond, Verdict: framework.VerdictNoResult,
+			Message: `panic: index <0> & "1", out of range`,
+		},
+		{
+			Problem: 99, Title: "Unknown", Method: "guess",
+			Result: int64Ptr(-7),
+			Status: framework.StatusOK, TimeCost: 3 * time.Microsecond, Verdict: framework.VerdictUnknown,
+		},
+	}}
+}
+
+// TestReportWriters checks output of every format, with special characters of formats in
+// titles, methods and messages.
+func TestReportWriters(t *testing.T) {
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: FormatJSON,
+			expected: `[
+  {
+    "problem": 14,
+    "title": "Longest Collatz sequence",
+    "method": "naive",
+    "result": 837799,
+    "expected": 837799,
+    "status": "ok",
+    "time_ns": 558397000,
+    "verdict": "correct"
+  },
+  {
+    "problem": 14,
+    "title": "Longest Collatz sequence",
+    "method": "a\u003cb \u0026 \"c\", d",
+    "result": 1,
+    "expected": 837799,
+    "status": "ok",
+    "time_ns": 1500000,
+    "verdict": "wrong"
+  },
+  {
+    "problem": 23,
+    "title": "Non-abundant sums | \"\u003c\u0026\u003e\", x",
+    "method": "naive",
+    "result": null,
+    "expected": 4179871,
+    "status": "timeout",
+    "time_ns": 1000000000,
+    "verdict": "no-result"
+  },
+  {
+    "problem": 23,
+    "title": "Non-abundant sums | \"\u003c\u0026\u003e\", x",
+    "method": "crash",
+    "result": null,
+    "expected": 4179871,
+    "status": "crashed",
+    "time_ns": 2000000,
+    "verdict": "no-result",
+    "message": "panic: index \u003c0\u003e \u0026 \"1\", out of range"
+  },
+  {
+    "problem": 99,
+    "title": "Unknown",
+    "method": "guess",
+    "result": -7,
+    "status": "ok",
+    "time_ns": 3000,
+    "verdict": "unknown"
+  }
+]
+`,
+		},
+		{
+			format: FormatCSV,
+			expected: `problem,title,method,result,status,time_ms,verdict
+14,Longest Collatz sequence,naive,837799,ok,558.397,correct
+14,Longest Collatz sequence,"a<b & ""c"", d",1,ok,1.500,wrong
+23,"Non-abundant sums | ""<&>"", x",naive,,timeout,1000.000,no-result
+23,"Non-abundant sums | ""<&>"", x",crash,,crashed,2.000,no-result
+99,Unknown,guess,-7,ok,0.003,unknown
+`,
+		},
+		{
+			format: FormatMarkdown,
+			expected: `| Problem | Title | Method | Result | Status | Time (ms) | Verdict |
+|--------:|-------|--------|-------:|--------|----------:|---------|
+| 14 | Longest Collatz sequence | naive | 837799 | ok | 558.397 | correct |
+| 14 | Longest Collatz sequence | a<b & "c", d | 1 | ok | 1.500 | wrong |
+| 23 | Non-abundant sums \| "<&>", x | naive |  | timeout | 1000.000 | no-result |
+| 23 | Non-abundant sums \| "<&>", x | crash |  | crashed | 2.000 | no-result |
+| 99 | Unknown | guess | -7 | ok | 0.003 | unknown |
+`,
+		},
+		{
+			format: FormatJUnit,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="p0014 Longest Collatz sequence" tests="2" failures="1" errors="0" time="0.559897">
+    <testcase classname="p0014" name="naive" time="0.558397"></testcase>
+    <testcase classname="p0014" name="a&lt;b &amp; &#34;c&#34;, d" time="0.001500">
+      <failure type="wrong" message="got 1, expected 837799"></failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="p0023 Non-abundant sums | &#34;&lt;&amp;&gt;&#34;, x" tests="2" failures="1" errors="1" time="1.002000">
+    <testcase classname="p0023" name="naive" time="1.000000">
+      <failure type="timeout" message="method timed out"></failure>
+    </testcase>
+    <testcase classname="p0023" name="crash" time="0.002000">
+      <error type="crashed" message="panic: index &lt;0&gt; &amp; &#34;1&#34;, out of range"></error>
+    </testcase>
+  </testsuite>
+  <testsuite name="p0099 Unknown" tests="1" failures="0" errors="0" time="0.000003">
+    <testcase classname="p0099" name="guess" time="0.000003">
+      <system-out>result -7, answer unknown</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		},
+	}
+
+	for _, c := range cases {
+		buffer := &bytes.Buffer{}
+		if err := reportWriters[c.format](buffer, makeTestReport()); err != nil {
+			t.Fatalf("write %s report failed: %v", c.format, err)
+		}
+
+		if buffer.String() != c.expected {
+			t.Errorf("wrong %s report:\n%s\nexpected:\n%s", c.format, buffer.String(), c.expected)
+		}
+	}
+
+	if len(cases) != len(reportWriters) {
+		t.Errorf("%d formats tested, but %d formats are supported", len(cases), len(reportWriters))
+	}
+}
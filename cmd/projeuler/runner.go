@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,21 +31,26 @@ func toMsString(d time.Duration) (float64, string) {
 	return msf, fmt.Sprintf("%10.3fms", msf)
 }
 
-func toMsColour(d time.Duration, isTimeout bool) string {
-	msf, mss := toMsString(d)
+// toMsColour colours time cost relative to budget of the method, it is red only if budget is
+// used up.
+func toMsColour(d time.Duration, isTimeout bool, budget time.Duration) string {
+	_, mss := toMsString(d)
 
 	var result string
 	switch {
 	case isTimeout:
 		result = mss
 
-	case msf < 100.0:
+	case budget <= 0:
+		result = mss
+
+	case d < budget/5:
 		result = color.GreenString(mss)
 
-	case msf < 200.0:
+	case d < budget*2/5:
 		result = color.CyanString(mss)
 
-	case msf < 500.0:
+	case d < budget:
 		result = color.YellowString(mss)
 
 	default:
@@ -53,25 +60,35 @@ func toMsColour(d time.Duration, isTimeout bool) string {
 	return result
 }
 
-func makeRunProblemEntryMap(problems []string) (map[int][]string, error) {
-	m := make(map[int][]string)
-	for _, problem := range problems {
-		info, err := framework.ParseProblemId(problem)
-		if err != nil {
-			return nil, err
-		}
+func workerArgs(conf *framework.Configure) []string {
+	args := []string{os.Args[0], "worker"}
+	switch conf.Transport {
+	case framework.TransportTCP:
+		args = append(args, "-port", "0", "-ready-fd", strconv.Itoa(framework.ReadyFd))
 
-		m[info.ProblemId] = append(m[info.ProblemId], info.Method)
+	case framework.TransportUnix:
+		args = append(args, "-socket", conf.RunSocket, "-ready-fd", strconv.Itoa(framework.ReadyFd))
+
+	default:
+		args = append(args, "-stdio")
 	}
 
-	return m, nil
+	args = append(args, conf.Limits.Args()...)
+	return args
 }
 
-func startWorker(conf *framework.Configure) *framework.WorkerProc {
-	args := []string{os.Args[0], "-worker", "-port", fmt.Sprintf("%d", conf.RunPort)}
-	files := []*os.File{nil, os.Stdout, nil}
-	if conf.DebugMode {
-		files[2] = os.Stderr
+// spawnWorker starts a worker process, files of stdin, stdout and readiness pipe are passed to
+// it, and they can be nil.
+func spawnWorker(conf *framework.Configure, stdin *os.File, stdout *os.File, ready *os.File) (*framework.WorkerProc, error) {
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	args := workerArgs(conf)
+	files := []*os.File{stdin, stdout, stderrWriter}
+	if ready != nil {
+		files = append(files, ready)
 	}
 
 	attrs := &os.ProcAttr{
@@ -79,104 +96,267 @@ func startWorker(conf *framework.Configure) *framework.WorkerProc {
 	}
 
 	proc, err := os.StartProcess(os.Args[0], args, attrs)
+	_ = stderrWriter.Close()
 	if err != nil {
-		panic(err)
+		_ = stderrReader.Close()
+		return nil, err
 	}
 
-	exitSignal := make(chan struct{})
-	go func() {
-		_, _ = proc.Wait()
-		exitSignal <- struct{}{}
-	}()
+	var echo io.Writer
+	if conf.DebugMode {
+		echo = os.Stderr
+	}
 
-	context, cancel := framework.NewTimeoutContext(100 * time.Millisecond)
-	defer cancel()
+	worker := framework.NewWorkerProc(proc, stderrReader, echo)
+	worker.Limits = conf.Limits
+	return worker, nil
+}
 
-	select {
-	case <-context.Done():
-		log.Printf("start background worker port=%d pid=%d", conf.RunPort, proc.Pid)
-		// subprocess started and not exited
+// startSocketWorker starts a worker listening on a TCP port assigned by system, or on a private
+// Unix socket, and connects to it after the worker reports its address on the readiness pipe.
+func startSocketWorker(conf *framework.Configure) (*framework.WorkerProc, *framework.Client, error) {
+	if conf.Transport == framework.TransportUnix {
+		_ = os.Remove(conf.RunSocket)
+	}
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer readyReader.Close()
+	worker, err := spawnWorker(conf, nil, os.Stdout, readyWriter)
+	_ = readyWriter.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	address, err := worker.WaitReady(readyReader, conf.ReadyTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := framework.NewClient(address)
+	if err != nil {
+		worker.Kill()
+		return nil, nil, err
+	}
+
+	log.Printf("start background worker %s pid=%d", address, worker.Pid())
+	return worker, client, nil
+}
+
+// startPipeWorker starts a worker talking through its stdin and stdout. The worker is ready as
+// soon as it is started, and its exit closes the pipe, which is seen by client immediately.
+func startPipeWorker(conf *framework.Configure) (*framework.WorkerProc, *framework.Client, error) {
+	childStdin, parentWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	case <-exitSignal:
-		// subprocess exited
-		log.Printf("failed to start background worker port=%d pid=%d", conf.RunPort, proc.Pid)
-		proc = nil
+	parentReader, childStdout, err := os.Pipe()
+	if err != nil {
+		_ = childStdin.Close()
+		_ = parentWriter.Close()
+		return nil, nil, err
+	}
+
+	worker, err := spawnWorker(conf, childStdin, childStdout, nil)
+	_ = childStdin.Close()
+	_ = childStdout.Close()
+	if err != nil {
+		_ = parentReader.Close()
+		_ = parentWriter.Close()
+		return nil, nil, err
 	}
 
-	return framework.NewWorkerProc(proc)
+	log.Printf("start background worker stdio pid=%d", worker.Pid())
+	client := framework.NewPipeClient(parentReader, parentWriter)
+	return worker, client, nil
 }
 
-func initConnection(conf *framework.Configure) (*framework.WorkerProc, *framework.Client) {
-	var worker *framework.WorkerProc
-	for worker == nil {
-		worker = startWorker(conf)
-		if worker == nil {
-			conf.RunPort += 1
-			if conf.RunPort > 1783 {
-				conf.RunPort = 1707
-			}
-		}
+func initConnection(conf *framework.Configure) (*framework.WorkerProc, *framework.Client, error) {
+	start := startPipeWorker
+	if conf.Transport != framework.TransportStdio {
+		start = startSocketWorker
 	}
 
-	client, err := conf.NewClient("127.0.0.1")
+	worker, client, err := start(conf)
 	if err != nil {
-		log.Printf("create client failed: %s\n", err)
-		panic(err)
+		return nil, nil, err
 	}
 
 	client.SetTimeout(conf.ProblemTimeout, conf.MethodTimeout)
-	return worker, client
+	return worker, client, nil
 }
 
-func runProblems(conf *framework.Configure, allProblems []framework.Problem) {
-	conf.RunPort = conf.ServePort
-	problemEntry, err := makeRunProblemEntryMap(conf.Problems)
+// runProblems runs selected methods and returns the report of all methods run. Methods run
+// before an error are still in the report.
+func runProblems(conf *framework.Configure, allProblems []framework.Problem) (*Report, error) {
+	if err := checkFormat(conf.Format); err != nil {
+		return nil, &UsageError{err}
+	}
+
+	selected, err := selectProblems(conf, allProblems)
 	if err != nil {
-		fmt.Printf("ERROR: %s\n", err)
-		return
+		return nil, &UsageError{err}
 	}
 
-	worker, client := initConnection(conf)
+	pool, err := NewWorkerPool(conf, conf.SpareWorkers)
+	if err != nil {
+		return nil, fmt.Errorf("start worker failed: %w", err)
+	}
+
+	timings, err := LoadTimings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: load recorded timings failed: %s\n", err)
+		timings = make(Timings)
+	}
+
+	isolate := IsolateStats{}
+	report := &Report{}
+	startedAt := time.Now()
 	defer func() {
-		worker.Kill()
-	}()
+		pool.Close()
+		if printsTable(conf) {
+			printPoolStats(pool.Stats())
+			if conf.Isolate {
+				printIsolateStats(isolate)
+			}
+		}
+
+		if err := timings.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: save timings failed: %s\n", err)
+		}
+
+		if err := appendRunHistory(conf, startedAt, report); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: save history failed: %s\n", err)
+		}
 
-	for _, problem := range allProblems {
-		methods, found := problemEntry[problem.Id]
-		if len(problemEntry) > 0 && !found {
-			continue
+		if err := WriteReport(conf, report); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: write report failed: %s\n", err)
 		}
+	}()
 
-		if methods == nil {
-			methods = problem.MethodList()
+	for _, selection := range selected {
+		problem, methods := selection.Problem, selection.Methods
+		if err := pool.Use(conf.Limits.Merge(problem.Limits)); err != nil {
+			return report, fmt.Errorf("start worker failed: %w", err)
 		}
 
 		finalResult := framework.NewResult()
 		for _, method := range methods {
-			resultSet, err := client.Run(problem.Id, method)
+			resultSet, err := runMethod(conf, pool, &isolate, problem, method)
 			if err != nil {
-				fmt.Printf("Run problem %d %s error: %s\n", problem.Id, method, err)
-				return
-			}
-
-			if resultSet.HasTimeoutedResult() {
-				client.Close()
-				worker.Kill()
-				time.Sleep(100 * time.Millisecond)
-				worker, client = initConnection(conf)
+				return report, fmt.Errorf("restart worker failed: %w", err)
 			}
 
 			finalResult.Append(resultSet)
 		}
 
-		printResult(conf, problem, finalResult)
+		timings.Update(finalResult, time.Now())
+		report.Add(problem, finalResult)
+		if printsTable(conf) {
+			printResult(conf, problem, finalResult)
+		}
+	}
+
+	return report, nil
+}
+
+// runMethod runs a method on the active worker of pool, or on a fresh one in isolate mode or
+// under CPU time limit. The worker is replaced if the method fails, and error is returned only
+// if it can not be replaced.
+func runMethod(conf *framework.Configure, pool *WorkerPool, isolate *IsolateStats,
+	problem framework.Problem, method string) (*framework.Result, error) {
+	if (conf.Isolate || pool.PerMethod()) && !pool.Fresh() {
+		switchStart := time.Now()
+		if err := pool.Replace(); err != nil {
+			return nil, err
+		}
+
+		isolate.Switch += time.Since(switchStart)
+	}
+
+	start := time.Now()
+	result, err := pool.Run(problem.Id, method, conf.MethodSettings(problem, method))
+	wall := time.Since(start)
+	if err != nil {
+		result = brokenResult(pool.Worker(), problem.Id, method, wall, err)
+	}
+
+	isolate.Methods++
+	isolate.RoundTrip += wall - result.TotalCost()
+
+	if result.HasFailedResult() {
+		if err := pool.Replace(); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// IsolateStats is the overhead of running each method in a fresh worker, it is not counted in
+// time cost of any method.
+type IsolateStats struct {
+	Methods int
+	// Switch is time spent on swapping in a fresh worker before methods.
+	Switch time.Duration
+	// RoundTrip is time spent on sending requests and receiving results, out of methods.
+	RoundTrip time.Duration
+}
+
+func printIsolateStats(stats IsolateStats) {
+	_, switchCost := toMsString(stats.Switch)
+	_, roundTrip := toMsString(stats.RoundTrip)
+	fmt.Printf("isolate: %d methods in fresh workers, overhead %s switching workers, %s round trip\n",
+		stats.Methods, strings.TrimSpace(switchCost), strings.TrimSpace(roundTrip))
+}
+
+// brokenResult makes result of a method whose worker stopped responding. It waits the worker to
+// exit, and tells whether it is killed by a resource limit or crashed.
+func brokenResult(worker *framework.WorkerProc, problemId int, method string,
+	cost time.Duration, err error) *framework.Result {
+	select {
+	case <-worker.Exited():
+	case <-time.After(time.Second):
+	}
+
+	result := framework.NewResult()
+	if status := worker.LimitExceeded(); status != "" {
+		result.AddFailedResult(problemId, method, status, cost)
+		return result
+	}
+
+	result.AddCrashedResult(problemId, method, cost, worker)
+	if !worker.HasExited() {
+		result.Results[0].ExitStatus = fmt.Sprintf("no response: %s", err)
+	}
+
+	return result
+}
+
+func statusString(status framework.ResultStatus) string {
+	switch status {
+	case framework.StatusMemoryLimit:
+		return "mem limit "
+
+	case framework.StatusCPULimit:
+		return "cpu limit "
+
+	case framework.StatusCrashed:
+		return "crashed   "
+
+	default:
+		return "timeout   "
 	}
 }
 
 func printResultItem(conf *framework.Configure, problem framework.Problem,
 	result framework.ResultItem, isBest bool) string {
 	parts := make([]string, 0, 3)
-	if result.IsTimeout {
+	if !result.HasResult() {
 
 		parts = append(parts,
 			//               1   5   10   15
@@ -186,8 +366,8 @@ func printResultItem(conf *framework.Configure, problem framework.Problem,
 	}
 
 	if conf.CheckMode {
-		if result.IsTimeout {
-			parts = append(parts, color.YellowString("timeout   "))
+		if !result.HasResult() {
+			parts = append(parts, color.YellowString(statusString(result.Status)))
 
 		} else if problem.NoAnswer {
 			parts = append(parts, color.YellowString("unknown   "))
@@ -200,7 +380,8 @@ func printResultItem(conf *framework.Configure, problem framework.Problem,
 		}
 	}
 
-	parts = append(parts, toMsColour(result.TimeCost, result.IsTimeout))
+	budget := conf.MethodSettings(problem, result.Method).MethodTimeout
+	parts = append(parts, toMsColour(result.TimeCost, !result.HasResult(), budget))
 
 	if isBest {
 		parts = append(parts, "*BEST")
@@ -242,6 +423,7 @@ func printResult(conf *framework.Configure, problem framework.Problem, result *f
 		resultColumn := printResultItem(conf, problem, item, false)
 		fmt.Printf("%-5d %-40s %s\n",
 			problem.Id, rightPadding(problem.Title, 40, "."), resultColumn)
+		printCrash(item)
 
 	} else {
 		printResultTitleWithMultipleResults(conf, problem, result)
@@ -250,6 +432,28 @@ func printResult(conf *framework.Configure, problem framework.Problem, result *f
 			resultColumn := printResultItem(conf, problem, item, best == i)
 			fmt.Printf("      + %-38s %s\n",
 				rightPadding(item.Method, 38, "."), resultColumn)
+			printCrash(item)
+		}
+	}
+}
+
+// crashStderrLines is the number of last stderr lines of a crashed worker printed.
+const crashStderrLines = 5
+
+func printCrash(item framework.ResultItem) {
+	if item.Status != framework.StatusCrashed {
+		return
+	}
+
+	fmt.Printf("      ! worker %s\n", color.RedString(item.ExitStatus))
+	lines := strings.Split(strings.TrimSpace(item.Stderr), "\n")
+	if len(lines) > crashStderrLines {
+		lines = lines[len(lines)-crashStderrLines:]
+	}
+
+	for _, line := range lines {
+		if line != "" {
+			fmt.Printf("      | %s\n", line)
 		}
 	}
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+func TestCPULimitPerMethod(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = os.Chdir(wd) }()
+
+	// Each method fits in the limit of 1 second, but both do not.
+	conf := &framework.Configure{}
+	fs := findCommand("run").FlagSet(conf)
+	conf.Problems = parseInterspersed(fs, []string{"-limit-cpu", "1s", "-method-timeout", "10s",
+		"-format", "json", "-output", os.DevNull, "1"})
+
+	report, err := runProblems(conf, testWorkerProblems)
+	if err != nil {
+		t.Fatalf("run failed: %s", err)
+	}
+
+	if len(report.Records) != 2 {
+		t.Fatalf("expected 2 methods run, got %d", len(report.Records))
+	}
+
+	for _, record := range report.Records {
+		if record.Status != framework.StatusOK {
+			t.Errorf("method '%s' should finish under limit of each method, got %s", record.Method, record.Status)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectStdout points fd 1 to stderr, and returns a new file of the original stdout to send
+// messages through. Output written to fd 1 in any way, like by C code or by writers holding
+// os.Stdout before, goes to stderr.
+func redirectStdout() (*os.File, error) {
+	stdout := int(os.Stdout.Fd())
+	fd, err := syscall.Dup(stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Dup3(int(os.Stderr.Fd()), stdout, 0); err != nil {
+		_ = syscall.Close(fd)
+		return nil, err
+	}
+
+	syscall.CloseOnExec(fd)
+	return os.NewFile(uintptr(fd), "stdout"), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+func TestStdioWorkerRedirectsFd(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = os.Chdir(wd) }()
+
+	// Methods write to fd 1 directly, and through writers holding stdout before worker starts.
+	conf := &framework.Configure{}
+	fs := findCommand("check").FlagSet(conf)
+	conf.Problems = parseInterspersed(fs, []string{"-transport", "stdio", "-format", "json",
+		"-output", os.DevNull, "2"})
+
+	// A corrupted stream may block reading a message never sent.
+	done := make(chan struct{})
+	var report *Report
+	var err error
+	go func() {
+		report, err = runProblems(conf, testWorkerProblems)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("run is blocked, message stream corrupted by output of methods")
+	}
+
+	if err != nil {
+		t.Fatalf("run failed: %s", err)
+	}
+
+	if len(report.Records) != 3 {
+		t.Fatalf("expected 3 methods run, got %d", len(report.Records))
+	}
+
+	for _, record := range report.Records {
+		if record.Status != framework.StatusOK || record.Verdict != framework.VerdictCorrect {
+			t.Errorf("method '%s' corrupted message stream, got %s %s %s", record.Method, record.Status,
+				record.Verdict, record.Message)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package main
+
+import (
+	"os"
+)
+
+// redirectStdout replaces os.Stdout with stderr, and returns the original stdout to send messages
+// through. Output written to fd 1 directly is not redirected.
+func redirectStdout() (*os.File, error) {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	return stdout, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+// stateDir is the directory keeping records of runs, in current working directory.
+const stateDir = ".projeuler"
+
+const timingsFile = "timings.json"
+
+// TimingRecord is the latest recorded run of a method.
+type TimingRecord struct {
+	Problem    int                    `json:"problem"`
+	Method     string                 `json:"method"`
+	Status     framework.ResultStatus `json:"status"`
+	Result     int64                  `json:"result"`
+	TimeCost   time.Duration          `json:"time_cost_ns"`
+	RecordedAt time.Time              `json:"recorded_at"`
+}
+
+// Timings are the latest recorded runs, indexed by problem id and method.
+type Timings map[string]TimingRecord
+
+func timingKey(problemId int, method string) string {
+	return fmt.Sprintf("%d.%s", problemId, method)
+}
+
+// LoadTimings reads recorded runs, no timing is recorded if the file does not exist yet.
+func LoadTimings() (Timings, error) {
+	timings := make(Timings)
+	data, err := os.ReadFile(filepath.Join(stateDir, timingsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return timings, nil
+
+	} else if err != nil {
+		return nil, err
+	}
+
+	records := make([]TimingRecord, 0)
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		timings[timingKey(record.Problem, record.Method)] = record
+	}
+
+	return timings, nil
+}
+
+func (t Timings) Save() error {
+	records := make([]TimingRecord, 0, len(t))
+	for _, record := range t {
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Problem != records[j].Problem {
+			return records[i].Problem < records[j].Problem
+		}
+
+		return records[i].Method < records[j].Method
+	})
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(stateDir, timingsFile), append(data, '\n'), 0o644)
+}
+
+// Update records all runs of methods in result.
+func (t Timings) Update(result *framework.Result, recordedAt time.Time) {
+	for _, item := range result.Results {
+		if item.Method == "" {
+			continue
+		}
+
+		t[timingKey(item.ProblemId, item.Method)] = TimingRecord{
+			Problem:    item.ProblemId,
+			Method:     item.Method,
+			Status:     item.Status,
+			Result:     item.Result,
+			TimeCost:   item.TimeCost,
+			RecordedAt: recordedAt,
+		}
+	}
+}
+
+// Get returns the latest recorded run of a method.
+func (t Timings) Get(problemId int, method string) (TimingRecord, bool) {
+	record, found := t[timingKey(problemId, method)]
+	return record, found
+}
+
+// slowThreshold is the time cost over which a method is known to be slow.
+const slowThreshold = 500 * time.Millisecond
+
+func (t Timings) Solved(problem framework.Problem) bool {
+	if problem.NoAnswer {
+		return false
+	}
+
+	for method := range problem.Methods {
+		record, found := t.Get(problem.Id, method)
+		if found && record.Status == framework.StatusOK && problem.Answer.Equals(record.Result) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t Timings) Slow(problem framework.Problem, method string) bool {
+	record, found := t.Get(problem.Id, method)
+	if !found {
+		return false
+	}
+
+	return record.Status == framework.StatusTimeout || record.TimeCost >= slowThreshold
+}
+
+// projectState is state of problems from recorded timings, with methods marked expected slow in
+// project config.
+type projectState struct {
+	Timings
+	conf *framework.Configure
+}
+
+func (s projectState) Slow(problem framework.Problem, method string) bool {
+	return s.conf.MethodSettings(problem, method).ExpectedSlow || s.Timings.Slow(problem, method)
+}
+
+// selectProblems resolves selection in arguments, with state of problems from recorded timings.
+// Methods skipped in project config are not selected, nor are slow methods unless they are
+// included or asked for explicitly.
+func selectProblems(conf *framework.Configure, allProblems []framework.Problem) ([]framework.ProblemSelection, error) {
+	selection, err := framework.ParseSelection(conf.Problems)
+	if err != nil {
+		return nil, err
+	}
+
+	timings, err := LoadTimings()
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selection.Resolve(allProblems, projectState{timings, conf})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]framework.ProblemSelection, 0, len(selected))
+	for _, s := range selected {
+		methods := make([]string, 0, len(s.Methods))
+		for _, method := range s.Methods {
+			settings := conf.MethodSettings(s.Problem, method)
+			if settings.Skip {
+				continue
+			}
+
+			if settings.ExpectedSlow && !conf.IncludeSlow && !selection.Explicit(s.Problem, method) {
+				continue
+			}
+
+			methods = append(methods, method)
+		}
+
+		if len(methods) > 0 {
+			s.Methods = methods
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+func validateFlags(fs *flag.FlagSet, conf *framework.Configure) {
+	fs.StringVar(&conf.ValidateRoot, "root", ".", "root `directory` of the repository")
+}
+
+// doValidate checks conventions of all problem packages, printing each violation with its file
+// and line.
+func doValidate(conf *framework.Configure) {
+	violations, err := framework.ValidateProblems(conf.ValidateRoot)
+	if err != nil {
+		os.Exit(errorExitCode(err))
+	}
+
+	for _, violation := range violations {
+		fmt.Println(violation)
+	}
+
+	if len(violations) > 0 {
+		fmt.Printf("%d violations found\n", len(violations))
+		os.Exit(ExitWrong)
+	}
+
+	fmt.Println("all problem packages are valid")
+}
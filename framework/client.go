@@ -1,6 +1,9 @@
 package framework
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/flily/projeuler.go/framework/connection"
@@ -13,8 +16,8 @@ type Client struct {
 	MethodTimeout  time.Duration
 }
 
-func NewClient(host string, port int) (*Client, error) {
-	client, err := connection.NewClient(host, port)
+func NewClient(address string) (*Client, error) {
+	client, err := connection.NewClient(address)
 	if err != nil {
 		return nil, err
 	}
@@ -26,21 +29,46 @@ func NewClient(host string, port int) (*Client, error) {
 	return c, nil
 }
 
+func NewPipeClient(r io.ReadCloser, w io.WriteCloser) *Client {
+	c := &Client{
+		client: connection.NewPipeClient(r, w),
+	}
+
+	return c
+}
+
 func (c *Client) Close() {
 	c.client.Close()
 }
 
+func (c *Client) SetCodec(codec message.Codec) {
+	c.client.SetCodec(codec)
+}
+
+func (c *Client) Shutdown(grace time.Duration) error {
+	return c.client.Shutdown(grace)
+}
+
 func (c *Client) SetTimeout(problemTimeout, methodTimeout time.Duration) {
 	c.ProblemTimeout = problemTimeout
 	c.MethodTimeout = methodTimeout
 }
 
 func (c *Client) Run(problemId int, method string) (*Result, error) {
+	return c.RunWithTimeout(problemId, method, c.ProblemTimeout, c.MethodTimeout)
+}
+
+// RunWithTimeout runs a method with timeouts other than those set on client.
+func (c *Client) RunWithTimeout(problemId int, method string,
+	problemTimeout, methodTimeout time.Duration) (*Result, error) {
 	request := message.NewRunMessage(problemId, method)
-	request.SetTimeout(c.ProblemTimeout, c.MethodTimeout)
+	request.SetTimeout(problemTimeout, methodTimeout)
 
 	resultMessage, err := c.client.Run(request)
-	if err != nil {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: %s", ErrWorkerExited, err)
+
+	} else if err != nil {
 		return nil, err
 	}
 
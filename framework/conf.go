@@ -2,62 +2,71 @@ package framework
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
-)
-
-type WorkerProc struct {
-	Proc *os.Process
-}
-
-func NewWorkerProc(proc *os.Process) *WorkerProc {
-	if proc == nil {
-		return nil
-	}
 
-	w := &WorkerProc{
-		Proc: proc,
-	}
+	"github.com/flily/projeuler.go/framework/connection"
+)
 
-	return w
-}
+const (
+	TransportStdio = "stdio"
+	TransportUnix  = "unix"
+	TransportTCP   = "tcp"
+)
 
-func (w *WorkerProc) Pid() int {
-	return w.Proc.Pid
+type Configure struct {
+	RunnerMode      bool
+	TotalTimeout    time.Duration
+	ClientMode      bool
+	WorkerMode      bool
+	StdioMode       bool
+	RawMode         bool
+	DebugMode       bool
+	ServePort       int
+	ServeSocket     string
+	Codec           string
+	Concurrency     int
+	Transport       string
+	SpareWorkers    int
+	Isolate         bool
+	IncludeSlow     bool
+	BenchCount      int
+	BenchSave       string
+	BenchCompare    string
+	BenchAlpha      float64
+	BenchThreshold  float64
+	JSONOutput      bool
+	HistoryLimit    int
+	HistoryByCommit bool
+	ReadmeFile      string
+	ReadmeCheck     bool
+	ValidateRoot    string
+	Format          string
+	Output          string
+	Limits          ResourceLimits
+	RunSocket       string
+	ReadyFd         int
+	ReadyTimeout    time.Duration
+	CheckMode       bool
+	FailOn          []string
+	ProblemTimeout  time.Duration
+	MethodTimeout   time.Duration
+	ShutdownGrace   time.Duration
+	Problems        []string
+	ConfigFile      string
+	Project         *ProjectConfig
+	// GivenFlags are names of flags given on command line, project config does not override them.
+	GivenFlags map[string]bool
 }
 
-func (w *WorkerProc) Kill() {
-	if w.Proc == nil {
-		return
-	}
-
-	err := w.Proc.Kill()
-	if err != nil {
-		fmt.Printf("stop worker (PID=%d) failed: %s", w.Proc.Pid, err)
-	} else {
-		w.Proc = nil
+// ServeAddress returns address of worker and client mode, Unix socket is used if it is given.
+func (c *Configure) ServeAddress() string {
+	if c.ServeSocket != "" {
+		return connection.UnixAddress(c.ServeSocket)
 	}
-}
-
-type Configure struct {
-	RunnerMode     bool
-	TotalTimeout   time.Duration
-	ClientMode     bool
-	WorkerMode     bool
-	RawMode        bool
-	DebugMode      bool
-	ServePort      int
-	RunPort        int
-	CheckMode      bool
-	ProblemTimeout time.Duration
-	MethodTimeout  time.Duration
-	Problems       []string
-}
 
-func (c *Configure) NewClient(host string) (*Client, error) {
-	return NewClient(host, c.RunPort)
+	return connection.TCPAddress("127.0.0.1", c.ServePort)
 }
 
 type ProblemRunInfo struct {
@@ -0,0 +1,39 @@
+package connection
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	UnixAddressPrefix = "unix:"
+	TCPAddressPrefix  = "tcp:"
+)
+
+// ParseAddress splits a transport address into network and address for net.Dial and net.Listen.
+// Address with prefix "unix:", or containing a path separator, is a Unix domain socket path,
+// otherwise it is a TCP address "host:port", optionally with prefix "tcp:".
+func ParseAddress(address string) (string, string) {
+	switch {
+	case strings.HasPrefix(address, UnixAddressPrefix):
+		return "unix", address[len(UnixAddressPrefix):]
+
+	case strings.HasPrefix(address, TCPAddressPrefix):
+		return "tcp", address[len(TCPAddressPrefix):]
+
+	case strings.Contains(address, "/"):
+		return "unix", address
+
+	default:
+		return "tcp", address
+	}
+}
+
+func TCPAddress(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func UnixAddress(path string) string {
+	return UnixAddressPrefix + path
+}
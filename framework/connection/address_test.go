@@ -0,0 +1,37 @@
+package connection
+
+import (
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	cases := []struct {
+		address string
+		network string
+		addr    string
+	}{
+		{"127.0.0.1:8080", "tcp", "127.0.0.1:8080"},
+		{"tcp:127.0.0.1:0", "tcp", "127.0.0.1:0"},
+		{"localhost:9999", "tcp", "localhost:9999"},
+		{"[::1]:8080", "tcp", "[::1]:8080"},
+		{"unix:worker.sock", "unix", "worker.sock"},
+		{"unix:/tmp/worker.sock", "unix", "/tmp/worker.sock"},
+		{"/tmp/worker.sock", "unix", "/tmp/worker.sock"},
+		{"./worker.sock", "unix", "./worker.sock"},
+	}
+
+	for _, c := range cases {
+		network, addr := ParseAddress(c.address)
+		if network != c.network || addr != c.addr {
+			t.Errorf("ParseAddress(%q): expected %s %s, got %s %s", c.address, c.network, c.addr, network, addr)
+		}
+	}
+
+	if address := UnixAddress("/tmp/worker.sock"); address != "unix:/tmp/worker.sock" {
+		t.Errorf("wrong Unix address %s", address)
+	}
+
+	if address := TCPAddress("::1", 8080); address != "[::1]:8080" {
+		t.Errorf("wrong TCP address %s", address)
+	}
+}
@@ -1,55 +1,95 @@
 package connection
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"net"
+	"time"
 
 	"github.com/flily/projeuler.go/framework/message"
 )
 
 type Client struct {
-	conn net.Conn
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	codec  message.Codec
 }
 
-func NewClient(host string, port int) (*Client, error) {
-	address := fmt.Sprintf("[%s]:%d", host, port)
-	conn, err := net.Dial("tcp", address)
+func NewClient(address string) (*Client, error) {
+	network, addr := ParseAddress(address)
+	conn, err := net.Dial(network, addr)
 	if err != nil {
 		return nil, err
 	}
 
 	c := &Client{
-		conn: conn,
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		codec:  message.BinaryCodec{},
 	}
 
 	return c, nil
 }
 
+// NewPipeClient creates a client talking to a worker through a pair of pipes, normally the
+// stdin and stdout of the worker process.
+func NewPipeClient(r io.ReadCloser, w io.WriteCloser) *Client {
+	conn := newPipe(r, w)
+	c := &Client{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		codec:  message.BinaryCodec{},
+	}
+
+	return c
+}
+
+func (c *Client) SetCodec(codec message.Codec) {
+	c.codec = codec
+}
+
 func (c *Client) Close() {
 	_ = c.conn.Close()
 }
 
-func (c *Client) Run(request *message.MessageRun) (*message.MessageResult, error) {
-	packet, err := request.Serialize()
-	if err != nil {
+func (c *Client) request(request message.Message) (message.Message, error) {
+	if err := c.codec.Encode(c.conn, request); err != nil {
 		return nil, err
 	}
 
-	_, err = c.conn.Write(packet)
+	return c.codec.Decode(c.reader)
+}
+
+func (c *Client) Run(request *message.MessageRun) (*message.MessageResult, error) {
+	reply, err := c.request(request)
 	if err != nil {
 		return nil, err
 	}
 
-	buffer := make([]byte, 16*1024)
-	n, err := c.conn.Read(buffer)
-	if err != nil {
-		return nil, err
+	result, ok := reply.(*message.MessageResult)
+	if !ok {
+		return nil, fmt.Errorf("expect result message, got '%s'", reply.Type())
 	}
 
-	result, err := message.DeserializeResult(buffer[:n], 0)
+	return result, nil
+}
+
+func (c *Client) Ping(sequence uint32) (*message.MessagePing, error) {
+	reply, err := c.request(message.NewPingMessage(sequence))
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	pong, ok := reply.(*message.MessagePing)
+	if !ok || pong.Command != message.MessageType_Pong {
+		return nil, fmt.Errorf("expect pong message, got '%s'", reply.Type())
+	}
+
+	return pong, nil
+}
+
+// Shutdown asks worker to shut down, worker does not reply but closes connection when it exits.
+func (c *Client) Shutdown(grace time.Duration) error {
+	return c.codec.Encode(c.conn, message.NewShutdownMessage(grace))
 }
@@ -0,0 +1,35 @@
+package connection
+
+import (
+	"io"
+)
+
+// pipe joins the read end and write end of a pair of pipes into a single connection, such as
+// stdin and stdout of a process.
+type pipe struct {
+	io.ReadCloser
+	writer io.WriteCloser
+}
+
+func newPipe(r io.ReadCloser, w io.WriteCloser) *pipe {
+	p := &pipe{
+		ReadCloser: r,
+		writer:     w,
+	}
+
+	return p
+}
+
+func (p *pipe) Write(data []byte) (int, error) {
+	return p.writer.Write(data)
+}
+
+func (p *pipe) Close() error {
+	errWrite := p.writer.Close()
+	errRead := p.ReadCloser.Close()
+	if errWrite != nil {
+		return errWrite
+	}
+
+	return errRead
+}
@@ -0,0 +1,33 @@
+package connection
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestPipeRoundTrip(t *testing.T) {
+	workerIn, clientOut := io.Pipe()
+	clientIn, workerOut := io.Pipe()
+
+	w := NewPipeWorkerConn(workerIn, workerOut)
+	loop := make(chan error, 1)
+	go func() { loop <- w.RunLoop() }()
+	go serveEcho(w)
+
+	client := NewPipeClient(clientIn, clientOut)
+	testRoundTrip(t, client)
+
+	client.Close()
+	select {
+	case err := <-loop:
+		if err != io.EOF {
+			t.Errorf("expected io.EOF after pipe closed, got %v", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Errorf("pipe worker does not return after client closed")
+	}
+
+	w.Close()
+}
@@ -0,0 +1,185 @@
+package connection
+
+import (
+	"io"
+	"sync"
+
+	"github.com/flily/projeuler.go/framework/message"
+)
+
+// Request is a run request received from a connection, its result is sent back to the same
+// connection by Reply.
+type Request struct {
+	Run     *message.MessageRun
+	session *session
+	sched   *scheduler
+}
+
+func (r *Request) Reply(result *message.MessageResult) error {
+	defer r.sched.done(r.session)
+	return r.session.send(result)
+}
+
+// session is state of a client connection. Each session has its own reader and writer, and at
+// most one request of a session is running at any time, so results are returned in order.
+type session struct {
+	conn    io.ReadWriteCloser
+	codec   message.Codec
+	lock    sync.Mutex
+	pending []*message.MessageRun
+	busy    bool
+}
+
+func newSession(conn io.ReadWriteCloser, codec message.Codec) *session {
+	s := &session{
+		conn:  conn,
+		codec: codec,
+	}
+
+	return s
+}
+
+func (s *session) send(m message.Message) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.codec.Encode(s.conn, m)
+}
+
+// scheduler queues run requests of all sessions, and picks them in round-robin order across
+// sessions, so a client sending many requests can not starve the others.
+type scheduler struct {
+	lock     sync.Mutex
+	cond     *sync.Cond
+	sessions []*session
+	next     int
+	closed   bool
+}
+
+func newScheduler() *scheduler {
+	s := &scheduler{}
+	s.cond = sync.NewCond(&s.lock)
+	return s
+}
+
+func (s *scheduler) add(sess *session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sessions = append(s.sessions, sess)
+}
+
+func (s *scheduler) remove(sess *session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, item := range s.sessions {
+		if item == sess {
+			s.sessions = append(s.sessions[:i], s.sessions[i+1:]...)
+			if s.next > i {
+				s.next--
+			}
+
+			break
+		}
+	}
+
+	sess.pending = nil
+}
+
+// push queues request of session, it returns false if scheduler is closed.
+func (s *scheduler) push(sess *session, run *message.MessageRun) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	sess.pending = append(sess.pending, run)
+	s.cond.Broadcast()
+	return true
+}
+
+func (s *scheduler) done(sess *session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sess.busy = false
+	s.cond.Broadcast()
+}
+
+// pop blocks until a request is available, or returns false if scheduler is closed.
+func (s *scheduler) pop() (*Request, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for {
+		if s.closed {
+			return nil, false
+		}
+
+		count := len(s.sessions)
+		for i := 0; i < count; i++ {
+			index := (s.next + i) % count
+			sess := s.sessions[index]
+			if sess.busy || len(sess.pending) <= 0 {
+				continue
+			}
+
+			run := sess.pending[0]
+			sess.pending = sess.pending[1:]
+			sess.busy = true
+			s.next = (index + 1) % count
+
+			request := &Request{
+				Run:     run,
+				session: sess,
+				sched:   s,
+			}
+
+			return request, true
+		}
+
+		s.cond.Wait()
+	}
+}
+
+// drain removes requests queued in all sessions and returns them, so they can be answered
+// instead of dropped silently.
+func (s *scheduler) drain() []*Request {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	requests := make([]*Request, 0)
+	for _, sess := range s.sessions {
+		for _, run := range sess.pending {
+			requests = append(requests, &Request{
+				Run:     run,
+				session: sess,
+				sched:   s,
+			})
+		}
+
+		sess.pending = nil
+	}
+
+	return requests
+}
+
+// closeSessions closes connections of all sessions, pending requests are dropped.
+func (s *scheduler) closeSessions() {
+	s.lock.Lock()
+	sessions := append([]*session{}, s.sessions...)
+	s.lock.Unlock()
+
+	for _, sess := range sessions {
+		_ = sess.conn.Close()
+	}
+}
+
+func (s *scheduler) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	s.cond.Broadcast()
+}
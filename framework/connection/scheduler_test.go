@@ -0,0 +1,131 @@
+package connection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flily/projeuler.go/framework/message"
+)
+
+func TestSchedulerRoundRobin(t *testing.T) {
+	sched := newScheduler()
+	a, b := newSession(nil, nil), newSession(nil, nil)
+	sched.add(a)
+	sched.add(b)
+
+	for i := 1; i <= 3; i++ {
+		sched.push(a, message.NewRunMessage(i, "a"))
+	}
+	sched.push(b, message.NewRunMessage(1, "b"))
+
+	expected := []string{"a", "b", "a", "a"}
+	for i, method := range expected {
+		request, ok := sched.pop()
+		if !ok {
+			t.Fatalf("pop %d failed", i)
+		}
+
+		if request.Run.Method != method {
+			t.Errorf("pop %d expected method %s, got %s", i, method, request.Run.Method)
+		}
+
+		sched.done(request.session)
+	}
+}
+
+func TestSchedulerOneRunningRequestPerSession(t *testing.T) {
+	sched := newScheduler()
+	a, b := newSession(nil, nil), newSession(nil, nil)
+	sched.add(a)
+	sched.add(b)
+
+	sched.push(a, message.NewRunMessage(1, "a"))
+	sched.push(a, message.NewRunMessage(2, "a"))
+	sched.push(b, message.NewRunMessage(1, "b"))
+
+	first, _ := sched.pop()
+	second, _ := sched.pop()
+	if first.session != a || second.session != b {
+		t.Errorf("expected requests of both sessions running")
+	}
+
+	sched.remove(a)
+	sched.close()
+	if _, ok := sched.pop(); ok {
+		t.Errorf("pop should fail after scheduler closed")
+	}
+}
+
+func TestWorkerConnSessionsNotStarved(t *testing.T) {
+	w, err := NewWorkerConn(TCPAddress("127.0.0.1", 0))
+	if err != nil {
+		t.Fatalf("listen failed: %s", err)
+	}
+
+	defer w.Close()
+	go func() { _ = w.RunLoop() }()
+
+	// Requests are run one by one, like a worker of concurrency 1.
+	served := make(chan string, 32)
+	go func() {
+		for request := range w.RecvRun() {
+			served <- request.Run.Method
+			time.Sleep(5 * time.Millisecond)
+			result := message.NewResult()
+			result.AddResult(message.NewResultItem(request.Run.Problem, request.Run.Method, 0, 0))
+			_ = request.Reply(result)
+		}
+	}()
+
+	// Session a queues many requests at once, without waiting for results.
+	const queued = 10
+	a, err := NewClient(w.Address())
+	if err != nil {
+		t.Fatalf("dial failed: %s", err)
+	}
+
+	defer a.Close()
+	for i := 0; i < queued; i++ {
+		if err := a.codec.Encode(a.conn, message.NewRunMessage(i, "a")); err != nil {
+			t.Fatalf("send request failed: %s", err)
+		}
+	}
+
+	if method := <-served; method != "a" {
+		t.Fatalf("expected request of session a served first, got %s", method)
+	}
+
+	b, err := NewClient(w.Address())
+	if err != nil {
+		t.Fatalf("dial failed: %s", err)
+	}
+
+	defer b.Close()
+	done := make(chan error, 1)
+	go func() {
+		_, err := b.Run(message.NewRunMessage(1, "b"))
+		done <- err
+	}()
+
+	// Session b is served right after the running request of session a, or one more of a
+	// picked before b connected, not after the whole queue of a.
+	for i := 1; i < queued; i++ {
+		if method := <-served; method == "b" {
+			if i > 2 {
+				t.Errorf("request of session b served after %d requests of session a", i)
+			}
+
+			break
+		}
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("run of session b failed: %s", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Errorf("session b is starved by queue of session a")
+	}
+}
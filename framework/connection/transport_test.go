@@ -0,0 +1,75 @@
+package connection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flily/projeuler.go/framework/message"
+)
+
+// serveEcho answers every run request of w with a result whose value is the problem id.
+func serveEcho(w *WorkerConn) {
+	for request := range w.RecvRun() {
+		result := message.NewResult()
+		result.AddResult(message.NewResultItem(request.Run.Problem, request.Run.Method,
+			int64(request.Run.Problem), time.Millisecond))
+		_ = request.Reply(result)
+	}
+}
+
+func testRoundTrip(t *testing.T, client *Client) {
+	t.Helper()
+	pong, err := client.Ping(7)
+	if err != nil {
+		t.Fatalf("ping failed: %s", err)
+	}
+
+	if pong.Sequence != ^uint32(7) {
+		t.Errorf("expected pong of inverted sequence 7, got %d", pong.Sequence)
+	}
+
+	result, err := client.Run(message.NewRunMessage(14, "naive"))
+	if err != nil {
+		t.Fatalf("run failed: %s", err)
+	}
+
+	if len(result.Results) != 1 || result.Results[0].Result != 14 || result.Results[0].Method != "naive" {
+		t.Errorf("unexpected result: %+v", result.Results)
+	}
+}
+
+func testListenerRoundTrip(t *testing.T, address string) {
+	w, err := NewWorkerConn(address)
+	if err != nil {
+		t.Fatalf("listen on %s failed: %s", address, err)
+	}
+
+	defer w.Close()
+	go func() { _ = w.RunLoop() }()
+	go serveEcho(w)
+
+	client, err := NewClient(w.Address())
+	if err != nil {
+		t.Fatalf("dial %s failed: %s", w.Address(), err)
+	}
+
+	defer client.Close()
+	testRoundTrip(t, client)
+}
+
+func TestTCPRoundTrip(t *testing.T) {
+	testListenerRoundTrip(t, TCPAddress("127.0.0.1", 0))
+}
+
+func TestUnixRoundTrip(t *testing.T) {
+	dir, err := os.MkdirTemp("", "projeuler")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Path of Unix socket is limited to about 100 bytes, t.TempDir may be too long.
+	defer os.RemoveAll(dir)
+	testListenerRoundTrip(t, UnixAddress(filepath.Join(dir, "worker.sock")))
+}
@@ -1,89 +1,219 @@
 package connection
 
 import (
+	"bufio"
 	"errors"
-	"fmt"
 	"io"
 	"log"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/flily/projeuler.go/framework/message"
 )
 
 type WorkerConn struct {
-	port       int
+	address    string
 	listener   net.Listener
-	sendQueue  chan *message.MessageResult
-	recvQueue  chan *message.MessageRun
+	pipe       io.ReadWriteCloser
+	codec      message.Codec
+	sched      *scheduler
+	recvQueue  chan *Request
 	stopSignal chan struct{}
+	stopOnce   sync.Once
+	onShutdown func(grace time.Duration)
+	onDrop     func(request *Request)
 }
 
-func NewWorkerConn(host string, port int) (*WorkerConn, error) {
-	address := fmt.Sprintf("%s:%d", host, port)
-	listener, err := net.Listen("tcp", address)
+func newWorkerConn(address string) *WorkerConn {
+	w := &WorkerConn{
+		address:    address,
+		sched:      newScheduler(),
+		recvQueue:  make(chan *Request),
+		stopSignal: make(chan struct{}),
+	}
+
+	go w.dispatch()
+	return w
+}
+
+func NewWorkerConn(address string) (*WorkerConn, error) {
+	network, addr := ParseAddress(address)
+	listener, err := net.Listen(network, addr)
 	if err != nil {
 		return nil, err
 	}
 
-	w := &WorkerConn{
-		port:       port,
-		listener:   listener,
-		sendQueue:  make(chan *message.MessageResult),
-		recvQueue:  make(chan *message.MessageRun),
-		stopSignal: make(chan struct{}),
+	w := newWorkerConn(address)
+	w.listener = listener
+	return w, nil
+}
+
+// NewPipeWorkerConn creates a worker connection serving exactly one client through a pair of
+// pipes, normally the stdin and stdout of the worker process.
+func NewPipeWorkerConn(r io.ReadCloser, wr io.WriteCloser) *WorkerConn {
+	w := newWorkerConn("stdio")
+	w.pipe = newPipe(r, wr)
+	return w
+}
+
+// Address returns the address actually listened on, with port assigned by system if port 0 is
+// given, so it can be dialed by client.
+func (w *WorkerConn) Address() string {
+	if w.listener == nil {
+		return w.address
 	}
 
-	return w, nil
+	addr := w.listener.Addr()
+	if addr.Network() == "unix" {
+		return UnixAddress(addr.String())
+	}
+
+	return TCPAddressPrefix + addr.String()
+}
+
+// SetCodec forces codec of all connections, codec is detected on each connection if it is nil.
+func (w *WorkerConn) SetCodec(codec message.Codec) {
+	w.codec = codec
+}
+
+// SetShutdownHandler sets function called when a shutdown message is received.
+func (w *WorkerConn) SetShutdownHandler(handler func(grace time.Duration)) {
+	w.onShutdown = handler
+}
+
+// SetDropHandler sets function called with each request not run because WorkerConn is stopped,
+// so it can be answered with an error.
+func (w *WorkerConn) SetDropHandler(handler func(request *Request)) {
+	w.onDrop = handler
+}
+
+func (w *WorkerConn) drop(request *Request) {
+	if w.onDrop != nil {
+		w.onDrop(request)
+	}
+}
+
+// Stop stops accepting new connections and new requests, the receive queue is closed after that.
+// Connections are kept open, so results of running requests can still be sent back. Requests
+// queued but not started are dropped.
+func (w *WorkerConn) Stop() {
+	w.stopOnce.Do(func() {
+		if w.listener != nil {
+			_ = w.listener.Close()
+		}
+
+		close(w.stopSignal)
+		w.sched.close()
+		for _, request := range w.sched.drain() {
+			w.drop(request)
+		}
+	})
 }
 
+// Close stops WorkerConn and closes all connections.
 func (w *WorkerConn) Close() {
-	_ = w.listener.Close()
-	close(w.sendQueue)
-	close(w.recvQueue)
+	w.Stop()
+	w.sched.closeSessions()
+	if w.pipe != nil {
+		_ = w.pipe.Close()
+	}
 }
 
+// RunLoop accepts connections until listener is closed, each connection is served in its own
+// goroutine. A pipe connection is served only once, and io.EOF is returned when the other side
+// closes it.
 func (w *WorkerConn) RunLoop() error {
-	buffer := make([]byte, 16*1024)
+	if w.pipe != nil {
+		w.serve(w.pipe)
+		return io.EOF
+	}
+
 	for {
 		conn, err := w.listener.Accept()
 		if err != nil {
 			return err
 		}
 
-		for {
-			readLength, err := conn.Read(buffer)
-			if err != nil {
-				if !errors.Is(err, io.EOF) {
-					log.Printf("ERROR on read: %s", err)
-				}
+		go func() {
+			w.serve(conn)
+			_ = conn.Close()
+		}()
+	}
+}
 
-				break
-			}
+func (w *WorkerConn) serve(conn io.ReadWriteCloser) {
+	reader := bufio.NewReader(conn)
+	codec := w.codec
+	if codec == nil {
+		detected, err := message.DetectCodec(reader)
+		if err != nil {
+			return
+		}
+
+		codec = detected
+	}
+
+	sess := newSession(conn, codec)
+	w.sched.add(sess)
+	defer w.sched.remove(sess)
 
-			request, err := message.DeserializeRunMessage(buffer[:readLength], 0)
-			if err != nil {
-				log.Printf("ERROR on deserialize: %s", err)
-				break
+	for {
+		request, err := codec.Decode(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Printf("ERROR on read: %s", err)
 			}
 
-			w.recvQueue <- request
+			return
+		}
 
-			result := <-w.sendQueue
-			packet, _ := result.Serialize()
+		switch m := request.(type) {
+		case *message.MessagePing:
+			err = sess.send(m.MakePong())
 
-			_, err = conn.Write(packet)
-			if err != nil {
-				log.Printf("ERROR on write: %s", err)
-				break
+		case *message.MessageRun:
+			if !w.sched.push(sess, m) {
+				w.drop(&Request{Run: m, session: sess, sched: w.sched})
 			}
+
+		case *message.MessageShutdown:
+			if w.onShutdown != nil {
+				w.onShutdown(m.Grace)
+			}
+
+		default:
+			log.Printf("ERROR unexpected message '%s'", request.Type())
+			return
+		}
+
+		if err != nil {
+			log.Printf("ERROR on write: %s", err)
+			return
 		}
 	}
 }
 
-func (w *WorkerConn) RecvRun() <-chan *message.MessageRun {
-	return w.recvQueue
+// dispatch forwards requests picked by scheduler to receive queue, and closes the queue after
+// WorkerConn is closed.
+func (w *WorkerConn) dispatch() {
+	defer close(w.recvQueue)
+
+	for {
+		request, ok := w.sched.pop()
+		if !ok {
+			return
+		}
+
+		select {
+		case w.recvQueue <- request:
+		case <-w.stopSignal:
+			w.drop(request)
+			return
+		}
+	}
 }
 
-func (w *WorkerConn) SendResult(result *message.MessageResult) {
-	w.sendQueue <- result
+func (w *WorkerConn) RecvRun() <-chan *Request {
+	return w.recvQueue
 }
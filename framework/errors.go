@@ -7,4 +7,5 @@ import (
 var (
 	ErrNoSuchProblem  = fmt.Errorf("no such problem")
 	ErrNoSuchSolution = fmt.Errorf("no such solution")
+	ErrWorkerExited   = fmt.Errorf("worker exited")
 )
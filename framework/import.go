@@ -21,6 +21,11 @@ func parseFunctionFullName(fullName string) (string, string) {
 	return packageName, functionName
 }
 
+// DataFilePath returns path of a file in data directory.
+func DataFilePath(name string) string {
+	return fmt.Sprintf("%s/%s", projectDataPath, name)
+}
+
 func Import() ([]byte, error) {
 	pc, _, _, _ := runtime.Caller(1)
 	f := runtime.FuncForPC(pc)
@@ -29,7 +34,7 @@ func Import() ([]byte, error) {
 
 	parts := strings.Split(packageName, "/")
 	packageIndex := parts[len(parts)-1]
-	dataFilename := fmt.Sprintf("%s/%s.txt", projectDataPath, packageIndex)
+	dataFilename := DataFilePath(packageIndex + ".txt")
 
 	fd, err := os.Open(dataFilename)
 	if err != nil {
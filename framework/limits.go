@@ -0,0 +1,120 @@
+package framework
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResourceLimits are limits of worker process, zero value of each field means no limit.
+type ResourceLimits struct {
+	// Memory is the maximum size of data segments in bytes, including heap and stacks.
+	Memory int64
+	// CPUTime is the maximum CPU time of worker process. Runner starts a fresh worker for each
+	// method under CPU time limit, so it is the limit of each method.
+	CPUTime time.Duration
+	// OpenFiles is the maximum number of open files.
+	OpenFiles int
+}
+
+func (l ResourceLimits) IsZero() bool {
+	return l == ResourceLimits{}
+}
+
+// Merge returns limits overridden by non-zero fields of override.
+func (l ResourceLimits) Merge(override ResourceLimits) ResourceLimits {
+	if override.Memory > 0 {
+		l.Memory = override.Memory
+	}
+
+	if override.CPUTime > 0 {
+		l.CPUTime = override.CPUTime
+	}
+
+	if override.OpenFiles > 0 {
+		l.OpenFiles = override.OpenFiles
+	}
+
+	return l
+}
+
+// Args returns command line arguments passing limits to worker process.
+func (l ResourceLimits) Args() []string {
+	args := make([]string, 0, 6)
+	if l.Memory > 0 {
+		args = append(args, "-limit-memory", strconv.FormatInt(l.Memory, 10))
+	}
+
+	if l.CPUTime > 0 {
+		args = append(args, "-limit-cpu", l.CPUTime.String())
+	}
+
+	if l.OpenFiles > 0 {
+		args = append(args, "-limit-files", strconv.Itoa(l.OpenFiles))
+	}
+
+	return args
+}
+
+func (l ResourceLimits) String() string {
+	if l.IsZero() {
+		return "unlimited"
+	}
+
+	parts := make([]string, 0, 3)
+	if l.Memory > 0 {
+		parts = append(parts, "memory="+FormatByteSize(l.Memory))
+	}
+
+	if l.CPUTime > 0 {
+		parts = append(parts, "cpu="+l.CPUTime.String())
+	}
+
+	if l.OpenFiles > 0 {
+		parts = append(parts, "files="+strconv.Itoa(l.OpenFiles))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"K", 1 << 10},
+	{"M", 1 << 20},
+	{"G", 1 << 30},
+	{"T", 1 << 40},
+}
+
+// ParseByteSize parses size like "512M" or "1G", units are power of 1024.
+func ParseByteSize(s string) (int64, error) {
+	text := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	unit := int64(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(text, u.suffix) {
+			text = strings.TrimSuffix(text, u.suffix)
+			unit = u.size
+			break
+		}
+	}
+
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid byte size '%s'", s)
+	}
+
+	return value * unit, nil
+}
+
+func FormatByteSize(size int64) string {
+	for i := len(byteSizeUnits) - 1; i >= 0; i-- {
+		u := byteSizeUnits[i]
+		if size >= u.size && size%u.size == 0 {
+			return fmt.Sprintf("%d%s", size/u.size, u.suffix)
+		}
+	}
+
+	return strconv.FormatInt(size, 10)
+}
@@ -0,0 +1,48 @@
+package framework
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Apply sets limits to current process, it is called by worker process at startup. Memory is
+// limited by RLIMIT_DATA rather than RLIMIT_AS, since Go runtime reserves a large address space
+// which is never used. The soft limit of CPU time is followed by a SIGXCPU, on which worker
+// exits with WorkerExitCPULimit, and the hard limit 1 second later kills worker for sure.
+func (l ResourceLimits) Apply() error {
+	if l.Memory > 0 {
+		limit := &syscall.Rlimit{Cur: uint64(l.Memory), Max: uint64(l.Memory)}
+		if err := syscall.Setrlimit(syscall.RLIMIT_DATA, limit); err != nil {
+			return err
+		}
+	}
+
+	if l.CPUTime > 0 {
+		seconds := uint64((l.CPUTime + 999_999_999) / 1_000_000_000)
+		limit := &syscall.Rlimit{Cur: seconds, Max: seconds + 1}
+		if err := syscall.Setrlimit(syscall.RLIMIT_CPU, limit); err != nil {
+			return err
+		}
+
+		go exitOnCPULimit()
+	}
+
+	if l.OpenFiles > 0 {
+		limit := &syscall.Rlimit{Cur: uint64(l.OpenFiles), Max: uint64(l.OpenFiles)}
+		if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, limit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exitOnCPULimit() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGXCPU)
+	<-signals
+	log.Printf("CPU time limit exceeded")
+	os.Exit(WorkerExitCPULimit)
+}
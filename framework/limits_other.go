@@ -0,0 +1,15 @@
+//go:build !linux
+
+package framework
+
+import (
+	"fmt"
+)
+
+func (l ResourceLimits) Apply() error {
+	if l.IsZero() {
+		return nil
+	}
+
+	return fmt.Errorf("resource limits are only supported on Linux")
+}
@@ -0,0 +1,133 @@
+package message
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+const (
+	CodecBinary = "binary"
+	CodecJSON   = "json"
+)
+
+// Message is the common interface of all messages in worker protocol.
+type Message interface {
+	Type() MessageType
+	Serialize() ([]byte, error)
+}
+
+func (h *MessageHeader) Type() MessageType {
+	return h.Command
+}
+
+// Codec encodes messages to and decodes messages from a stream.
+type Codec interface {
+	Name() string
+	Encode(w io.Writer, m Message) error
+	Decode(r *bufio.Reader) (Message, error)
+}
+
+func NewCodec(name string) (Codec, error) {
+	switch name {
+	case "", CodecBinary:
+		return BinaryCodec{}, nil
+
+	case CodecJSON:
+		return JSONCodec{}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown codec '%s'", name)
+	}
+}
+
+// DetectCodec peeks the first byte of stream and selects codec by it. A JSON line always starts
+// with '{' or whitespace, while a binary message starts with its command type, which MUST stay
+// below '\t' to keep detection unambiguous.
+func DetectCodec(r *bufio.Reader) (Codec, error) {
+	head, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch head[0] {
+	case '{', ' ', '\t', '\r', '\n':
+		return JSONCodec{}, nil
+
+	default:
+		return BinaryCodec{}, nil
+	}
+}
+
+// BinaryCodec is the default codec, messages are framed by total length in message header.
+type BinaryCodec struct{}
+
+func (c BinaryCodec) Name() string {
+	return CodecBinary
+}
+
+func (c BinaryCodec) Encode(w io.Writer, m Message) error {
+	packet, err := m.Serialize()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(packet)
+	return err
+}
+
+func (c BinaryCodec) Decode(r *bufio.Reader) (Message, error) {
+	head, err := r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+
+	header, _ := DeserializeHeader(head, 0)
+	if header.TotalLength < 4 {
+		return nil, fmt.Errorf("invalid message length %d", header.TotalLength)
+	}
+
+	packet := make([]byte, header.TotalLength)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return nil, err
+	}
+
+	return deserializeMessage(header.Command, packet)
+}
+
+type decodableMessage interface {
+	Message
+	DeserializeFrom(buffer []byte, offset int) (int, error)
+}
+
+func newMessage(command MessageType) (decodableMessage, error) {
+	switch command {
+	case MessageType_Ping, MessageType_Pong:
+		return &MessagePing{}, nil
+
+	case MessageType_Run:
+		return &MessageRun{}, nil
+
+	case MessageType_Result:
+		return &MessageResult{}, nil
+
+	case MessageType_Shutdown:
+		return &MessageShutdown{}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: '%d'", ErrUnknownMessage, command)
+	}
+}
+
+func deserializeMessage(command MessageType, packet []byte) (Message, error) {
+	m, err := newMessage(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.DeserializeFrom(packet, 0); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -4,4 +4,5 @@ import "fmt"
 
 var (
 	ErrBufferTooSmall = fmt.Errorf("buffer too small")
+	ErrUnknownMessage = fmt.Errorf("unknown message type")
 )
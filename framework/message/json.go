@@ -0,0 +1,291 @@
+package message
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+var messageTypeNames = map[MessageType]string{
+	MessageType_Unknown:  "unknown",
+	MessageType_Invalid:  "invalid",
+	MessageType_Ping:     "ping",
+	MessageType_Pong:     "pong",
+	MessageType_Run:      "run",
+	MessageType_Result:   "result",
+	MessageType_Shutdown: "shutdown",
+}
+
+func (t MessageType) String() string {
+	if name, found := messageTypeNames[t]; found {
+		return name
+	}
+
+	return strconv.Itoa(int(t))
+}
+
+func ParseMessageType(name string) (MessageType, error) {
+	for t, typeName := range messageTypeNames {
+		if typeName == name {
+			return t, nil
+		}
+	}
+
+	return MessageType_Unknown, fmt.Errorf("%w: '%s'", ErrUnknownMessage, name)
+}
+
+// jsonDuration is written as Go duration string like "1.5s", which can be parsed back without
+// loss. Integer of nanoseconds is also accepted on reading.
+type jsonDuration time.Duration
+
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(time.Duration(d).String())), nil
+}
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+
+		duration, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		*d = jsonDuration(duration)
+		return nil
+	}
+
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err != nil {
+		return fmt.Errorf("invalid duration %s", string(data))
+	}
+
+	*d = jsonDuration(ns)
+	return nil
+}
+
+type jsonPing struct {
+	Type     string `json:"type"`
+	Sequence uint32 `json:"sequence"`
+}
+
+func (m *MessagePing) MarshalJSON() ([]byte, error) {
+	wire := jsonPing{
+		Type:     m.Command.String(),
+		Sequence: m.Sequence,
+	}
+
+	return json.Marshal(wire)
+}
+
+func (m *MessagePing) UnmarshalJSON(data []byte) error {
+	wire := jsonPing{}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	command, err := ParseMessageType(wire.Type)
+	if err != nil {
+		return err
+	}
+
+	m.Command = command
+	m.Sequence = wire.Sequence
+	m.MessageLength()
+	return nil
+}
+
+type jsonRun struct {
+	Type           string       `json:"type"`
+	Problem        int          `json:"problem"`
+	Method         string       `json:"method"`
+	ProblemTimeout jsonDuration `json:"problem_timeout"`
+	MethodTimeout  jsonDuration `json:"method_timeout"`
+}
+
+func (m *MessageRun) MarshalJSON() ([]byte, error) {
+	wire := jsonRun{
+		Type:           m.Command.String(),
+		Problem:        m.Problem,
+		Method:         m.Method,
+		ProblemTimeout: jsonDuration(m.ProblemTimeout),
+		MethodTimeout:  jsonDuration(m.MethodTimeout),
+	}
+
+	return json.Marshal(wire)
+}
+
+func (m *MessageRun) UnmarshalJSON(data []byte) error {
+	wire := jsonRun{}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	m.Command = MessageType_Run
+	m.Problem = wire.Problem
+	m.Method = wire.Method
+	m.ProblemTimeout = time.Duration(wire.ProblemTimeout)
+	m.MethodTimeout = time.Duration(wire.MethodTimeout)
+	m.MessageLength()
+	return nil
+}
+
+type jsonResultItem struct {
+	Problem  int          `json:"problem"`
+	Method   string       `json:"method"`
+	Result   int64        `json:"result"`
+	Duration jsonDuration `json:"duration"`
+	Timeout  bool         `json:"timeout,omitempty"`
+	Finished bool         `json:"finished,omitempty"`
+	Error    bool         `json:"error,omitempty"`
+}
+
+type jsonResult struct {
+	Type    string           `json:"type"`
+	Results []jsonResultItem `json:"results"`
+	Message string           `json:"message"`
+}
+
+func (m *MessageResult) MarshalJSON() ([]byte, error) {
+	wire := jsonResult{
+		Type:    m.Command.String(),
+		Results: make([]jsonResultItem, 0, len(m.Results)),
+		Message: m.Message,
+	}
+
+	for _, item := range m.Results {
+		wire.Results = append(wire.Results, jsonResultItem{
+			Problem:  item.ProblemId,
+			Method:   item.Method,
+			Result:   item.Result,
+			Duration: jsonDuration(item.Duration),
+			Timeout:  item.IsTimeout,
+			Finished: item.IsFinished,
+			Error:    item.HasError,
+		})
+	}
+
+	return json.Marshal(wire)
+}
+
+func (m *MessageResult) UnmarshalJSON(data []byte) error {
+	wire := jsonResult{}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	m.Command = MessageType_Result
+	m.Message = wire.Message
+	m.ResultCount = len(wire.Results)
+	m.Results = make([]MessageResultItem, m.ResultCount)
+	for i, item := range wire.Results {
+		m.Results[i] = MessageResultItem{
+			ProblemId:  item.Problem,
+			Method:     item.Method,
+			Result:     item.Result,
+			Duration:   time.Duration(item.Duration),
+			IsTimeout:  item.Timeout,
+			IsFinished: item.Finished,
+			HasError:   item.Error,
+		}
+	}
+
+	m.MessageLength()
+	return nil
+}
+
+type jsonShutdown struct {
+	Type  string       `json:"type"`
+	Grace jsonDuration `json:"grace"`
+}
+
+func (m *MessageShutdown) MarshalJSON() ([]byte, error) {
+	wire := jsonShutdown{
+		Type:  m.Command.String(),
+		Grace: jsonDuration(m.Grace),
+	}
+
+	return json.Marshal(wire)
+}
+
+func (m *MessageShutdown) UnmarshalJSON(data []byte) error {
+	wire := jsonShutdown{}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	m.Command = MessageType_Shutdown
+	m.Grace = time.Duration(wire.Grace)
+	m.MessageLength()
+	return nil
+}
+
+// JSONCodec encodes each message as a single line of JSON object, with its type in field "type".
+type JSONCodec struct{}
+
+func (c JSONCodec) Name() string {
+	return CodecJSON
+}
+
+func (c JSONCodec) Encode(w io.Writer, m Message) error {
+	line, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+func (c JSONCodec) Decode(r *bufio.Reader) (Message, error) {
+	for {
+		line, err := r.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		return decodeJSONMessage(line)
+	}
+}
+
+func decodeJSONMessage(line []byte) (Message, error) {
+	header := struct {
+		Type string `json:"type"`
+	}{}
+
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, err
+	}
+
+	command, err := ParseMessageType(header.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMessage(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(line, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,141 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+)
+
+func makeTestMessages() []Message {
+	run := NewRunMessage(14, "with-cache-list")
+	run.SetTimeout(5*time.Second, 1500*time.Microsecond+7)
+
+	result := NewResult()
+	result.AddResult(&MessageResultItem{
+		ProblemId:  14,
+		Method:     "naive",
+		Result:     837799,
+		Duration:   558397*time.Microsecond + 13,
+		IsFinished: true,
+	})
+	result.AddResult(&MessageResultItem{
+		ProblemId: 23,
+		Method:    "naive",
+		Result:    -1,
+		Duration:  time.Second,
+		IsTimeout: true,
+		HasError:  true,
+	})
+	result.Message = "context deadline exceeded"
+	result.MessageLength()
+
+	ping := NewPingMessage(0x1a2b3c4d)
+	pong := ping.MakePong()
+	pong.MessageLength()
+
+	empty := NewResult()
+	empty.MessageLength()
+
+	shutdown := NewShutdownMessage(2*time.Second + 3)
+
+	return []Message{run, result, ping, pong, empty, shutdown}
+}
+
+func TestJSONCodecRoundTripWithBinary(t *testing.T) {
+	for _, m := range makeTestMessages() {
+		buffer := &bytes.Buffer{}
+		if err := (JSONCodec{}).Encode(buffer, m); err != nil {
+			t.Fatalf("encode %s failed: %v", m.Type(), err)
+		}
+
+		if !bytes.HasSuffix(buffer.Bytes(), []byte{'\n'}) ||
+			bytes.Count(buffer.Bytes(), []byte{'\n'}) != 1 {
+			t.Errorf("message %s is not a single line: %q", m.Type(), buffer.String())
+		}
+
+		fromJSON, err := (JSONCodec{}).Decode(bufio.NewReader(buffer))
+		if err != nil {
+			t.Fatalf("decode %s failed: %v", m.Type(), err)
+		}
+
+		packet := &bytes.Buffer{}
+		if err := (BinaryCodec{}).Encode(packet, fromJSON); err != nil {
+			t.Fatalf("binary encode %s failed: %v", m.Type(), err)
+		}
+
+		fromBinary, err := (BinaryCodec{}).Decode(bufio.NewReader(packet))
+		if err != nil {
+			t.Fatalf("binary decode %s failed: %v", m.Type(), err)
+		}
+
+		if !reflect.DeepEqual(m, fromJSON) {
+			t.Errorf("json round trip of %s\nexpected %+v\n     got %+v", m.Type(), m, fromJSON)
+		}
+
+		if !reflect.DeepEqual(m, fromBinary) {
+			t.Errorf("binary round trip of %s\nexpected %+v\n     got %+v", m.Type(), m, fromBinary)
+		}
+	}
+}
+
+func TestJSONCodecDecodeHandWritten(t *testing.T) {
+	input := strings.Join([]string{
+		``,
+		`{"type":"ping","sequence":42}`,
+		`  {"type":"run","problem":14,"method":"naive","method_timeout":"1s","problem_timeout":5000000000}`,
+	}, "\n")
+
+	reader := bufio.NewReader(strings.NewReader(input))
+	ping, err := (JSONCodec{}).Decode(reader)
+	if err != nil {
+		t.Fatalf("decode ping failed: %v", err)
+	}
+
+	if m, ok := ping.(*MessagePing); !ok || m.Sequence != 42 || m.Command != MessageType_Ping {
+		t.Errorf("wrong ping message: %+v", ping)
+	}
+
+	run, err := (JSONCodec{}).Decode(reader)
+	if err != nil {
+		t.Fatalf("decode run failed: %v", err)
+	}
+
+	expected := NewRunMessage(14, "naive")
+	expected.SetTimeout(5*time.Second, time.Second)
+	if m, ok := run.(*MessageRun); !ok || *m != *expected {
+		t.Errorf("expected %+v, got %+v", expected, run)
+	}
+}
+
+func TestJSONCodecDecodeUnknownType(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(`{"type":"lorem"}` + "\n"))
+	if _, err := (JSONCodec{}).Decode(reader); err == nil {
+		t.Errorf("decode should fail")
+	}
+}
+
+func TestDetectCodec(t *testing.T) {
+	cases := []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte(`{"type":"ping","sequence":1}`), CodecJSON},
+		{[]byte("\n{}"), CodecJSON},
+		{[]byte{byte(MessageType_Ping), 0x00, 0x00, 0x08}, CodecBinary},
+	}
+
+	for _, c := range cases {
+		codec, err := DetectCodec(bufio.NewReader(bytes.NewReader(c.input)))
+		if err != nil {
+			t.Fatalf("detect codec failed: %v", err)
+		}
+
+		if codec.Name() != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, codec.Name())
+		}
+	}
+}
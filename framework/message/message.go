@@ -9,12 +9,13 @@ type MessageType byte
 
 const (
 	// Message types
-	MessageType_Unknown MessageType = 0
-	MessageType_Invalid MessageType = 1
-	MessageType_Ping    MessageType = 2
-	MessageType_Pong    MessageType = 3
-	MessageType_Run     MessageType = 4
-	MessageType_Result  MessageType = 5
+	MessageType_Unknown  MessageType = 0
+	MessageType_Invalid  MessageType = 1
+	MessageType_Ping     MessageType = 2
+	MessageType_Pong     MessageType = 3
+	MessageType_Run      MessageType = 4
+	MessageType_Result   MessageType = 5
+	MessageType_Shutdown MessageType = 6
 )
 
 const (
@@ -477,3 +478,65 @@ func DeserializeResult(buffer []byte, offset int) (*MessageResult, error) {
 
 	return message, nil
 }
+
+// MessageShutdown presents a message to stop worker. Worker stops accepting runs, waits running
+// methods in grace period, and cancels them after that.
+// +-----+-----+-----+-----+-----+-----+-----+-----+-----+-----+-----+-----+
+// |  Message Header (4B)  |     Grace period (int64, in nanoseconds)      |
+// +-----------------------+-----------------------------------------------+
+type MessageShutdown struct {
+	MessageHeader
+
+	Grace time.Duration
+}
+
+func NewShutdownMessage(grace time.Duration) *MessageShutdown {
+	m := &MessageShutdown{
+		MessageHeader: MessageHeader{
+			Command: MessageType_Shutdown,
+		},
+		Grace: grace,
+	}
+
+	m.MessageLength()
+	return m
+}
+
+func (m *MessageShutdown) MessageLength() int {
+	length := m.MessageHeader.MessageLength() + 8
+	m.TotalLength = length
+	return length
+}
+
+func (m *MessageShutdown) SerializeTo(buffer []byte, offset int) (int, error) {
+	length := m.MessageLength()
+	if offset+length > len(buffer) {
+		return 0, ErrBufferTooSmall
+	}
+
+	headerLength, _ := m.MessageHeader.SerializeTo(buffer, offset)
+	writeInt64(buffer, offset+headerLength, int64(m.Grace))
+	return length, nil
+}
+
+func (m *MessageShutdown) Serialize() ([]byte, error) {
+	length := m.MessageLength()
+	buffer := make([]byte, length)
+	_, _ = m.SerializeTo(buffer, 0)
+	return buffer, nil
+}
+
+func (m *MessageShutdown) DeserializeFrom(buffer []byte, offset int) (int, error) {
+	if offset+12 > len(buffer) {
+		return 0, ErrBufferTooSmall
+	}
+
+	headerLength, _ := m.MessageHeader.DeserializeFrom(buffer, offset)
+	if m.Command != MessageType_Shutdown {
+		return 0, fmt.Errorf("message is not ShutdownMessage, got '%d'", m.Command)
+	}
+
+	grace, readLength := readInt64(buffer, offset+headerLength)
+	m.Grace = time.Duration(grace)
+	return headerLength + readLength, nil
+}
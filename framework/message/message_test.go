@@ -311,3 +311,34 @@ func TestMessageResultItemSerialize(t *testing.T) {
 		t.Errorf("expected %v, got %v", item, newItem)
 	}
 }
+
+func TestMessageShutdownSerialize(t *testing.T) {
+	message := NewShutdownMessage(3 * time.Second)
+
+	expected := []byte{
+		byte(MessageType_Shutdown), 0x00, 0x00, 0x0c, // header
+		0x00, 0x00, 0x00, 0x00, 0xb2, 0xd0, 0x5e, 0x00, // grace
+	}
+
+	got, err := message.Serialize()
+	if err != nil {
+		t.Errorf("serialize failed: %v", err)
+	}
+
+	if !bytes.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	newMessage := &MessageShutdown{}
+	if _, err := newMessage.DeserializeFrom(got, 0); err != nil {
+		t.Errorf("deserialize failed: %v", err)
+	}
+
+	if *newMessage != *message {
+		t.Errorf("expected %v, got %v", message, newMessage)
+	}
+
+	if _, err := newMessage.DeserializeFrom(got[:8], 0); err == nil {
+		t.Errorf("deserialize should fail")
+	}
+}
@@ -1,6 +1,7 @@
 package framework
 
 import (
+	"runtime"
 	"sort"
 	"strings"
 	"testing"
@@ -28,27 +29,115 @@ func (a Answer) Equals(b int64) bool {
 }
 
 type TestContext struct {
-	t        *testing.T
+	t        testing.TB
 	answer   Answer
 	noAnswer bool
+	// within and maxAllocBytes are limits of the best run, not checked if they are 0.
+	within        time.Duration
+	maxAllocBytes uint64
+	runs          int
 }
 
-func (c TestContext) On(solution Solution, name string) {
+// Within fails the test if method takes longer than d.
+func (c TestContext) Within(d time.Duration) TestContext {
+	c.within = d
+	return c
+}
+
+// MaxAllocBytes fails the test if method allocates more than n bytes in total.
+func (c TestContext) MaxAllocBytes(n uint64) TestContext {
+	c.maxAllocBytes = n
+	return c
+}
+
+// DefaultBestOf is number of runs of methods checked with limits, if BestOf is not given. Methods
+// checked only with answer run once.
+const DefaultBestOf = 3
+
+// BestOf runs method n times, and checks limits with the fastest and the least allocating runs,
+// so that a run slowed by noise, like scheduling or garbage collection, does not fail the test.
+func (c TestContext) BestOf(n int) TestContext {
+	c.runs = n
+	return c
+}
+
+// measure runs solution once, returning its result, time cost and bytes allocated. Allocations
+// of other goroutines running at the same time are counted too.
+func measure(solution Solution) (int64, time.Duration, uint64) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
 	got := solution()
+	cost := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return got, cost, after.TotalAlloc - before.TotalAlloc
+}
+
+func (c TestContext) On(solution Solution, name string) {
+	c.t.Helper()
+	runs := c.runs
+	if runs < 1 && (c.within > 0 || c.maxAllocBytes > 0) {
+		runs = DefaultBestOf
+
+	} else if runs < 1 {
+		runs = 1
+	}
+
+	got, bestCost, bestAlloc := measure(solution)
+	for i := 1; i < runs; i++ {
+		_, cost, alloc := measure(solution)
+		if cost < bestCost {
+			bestCost = cost
+		}
+
+		if alloc < bestAlloc {
+			bestAlloc = alloc
+		}
+	}
+
 	if c.noAnswer {
 		c.t.Logf("method '%s': %d", name, got)
 
 	} else if !c.answer.Equals(got) {
 		c.t.Errorf("Got wrong answer '%d' of method '%s', expect %d", got, name, c.answer)
 	}
+
+	if c.within > 0 && bestCost > c.within {
+		c.t.Errorf("Method '%s' took %s in best of %d runs, over limit %s", name, bestCost, runs, c.within)
+	}
+
+	if c.maxAllocBytes > 0 && bestAlloc > c.maxAllocBytes {
+		c.t.Errorf("Method '%s' allocated %d bytes in best of %d runs, over limit %d bytes",
+			name, bestAlloc, runs, c.maxAllocBytes)
+	}
 }
 
+// ResultStatus is the final state of running a method.
+type ResultStatus string
+
+const (
+	StatusOK          ResultStatus = "ok"
+	StatusTimeout     ResultStatus = "timeout"
+	StatusMemoryLimit ResultStatus = "memory-limit"
+	StatusCPULimit    ResultStatus = "cpu-limit"
+	StatusCrashed     ResultStatus = "crashed"
+)
+
 type ResultItem struct {
 	ProblemId int
 	Method    string
 	Result    int64
+	Status    ResultStatus
 	IsTimeout bool
 	TimeCost  time.Duration
+	// ExitStatus and Stderr describe the worker process exited while running the method.
+	ExitStatus string
+	Stderr     string
+}
+
+// HasResult tells whether the method has finished and returned a result.
+func (i *ResultItem) HasResult() bool {
+	return i.Status == StatusOK
 }
 
 func (i *ResultItem) ToMessage() *message.MessageResultItem {
@@ -63,6 +152,10 @@ func (i *ResultItem) FromMessage(message *message.MessageResultItem) {
 	i.Result = message.Result
 	i.TimeCost = message.Duration
 	i.IsTimeout = message.IsTimeout
+	i.Status = StatusOK
+	if i.IsTimeout {
+		i.Status = StatusTimeout
+	}
 }
 
 type Result struct {
@@ -83,16 +176,36 @@ func (r *Result) Add(item ResultItem) {
 }
 
 func (r *Result) AddTimeoutResult(problemId int, method string, cost time.Duration) {
+	r.AddFailedResult(problemId, method, StatusTimeout, cost)
+}
+
+// AddFailedResult adds result of a method finished without result.
+func (r *Result) AddFailedResult(problemId int, method string, status ResultStatus, cost time.Duration) {
 	item := ResultItem{
 		ProblemId: problemId,
 		Method:    method,
-		IsTimeout: true,
+		Status:    status,
+		IsTimeout: status == StatusTimeout,
 		TimeCost:  cost,
 	}
 
 	r.Add(item)
 }
 
+// AddCrashedResult adds result of a method during which the worker process exited unexpectedly.
+func (r *Result) AddCrashedResult(problemId int, method string, cost time.Duration, worker *WorkerProc) {
+	item := ResultItem{
+		ProblemId:  problemId,
+		Method:     method,
+		Status:     StatusCrashed,
+		TimeCost:   cost,
+		ExitStatus: worker.ExitStatus(),
+		Stderr:     worker.Stderr.String(),
+	}
+
+	r.Add(item)
+}
+
 func (r *Result) FindBest() int {
 	if r.Length() <= 0 {
 		return -1
@@ -111,7 +224,7 @@ func (r *Result) FindBest() int {
 func (r *Result) IsCorrect(answer Answer) bool {
 	result := false
 	for _, item := range r.Results {
-		if !item.IsTimeout && answer.Equals(item.Result) {
+		if item.HasResult() && answer.Equals(item.Result) {
 			result = true
 			break
 		}
@@ -149,6 +262,16 @@ func (r *Result) HasTimeoutedResult() bool {
 	return false
 }
 
+func (r *Result) HasFailedResult() bool {
+	for _, item := range r.Results {
+		if !item.HasResult() {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (r *Result) ToMessage() *message.MessageResult {
 	result := message.NewResult()
 
@@ -178,6 +301,41 @@ type Problem struct {
 	Answer      Answer
 	Methods     map[string]Solution
 	NoAnswer    bool
+	// Tags are topics of the problem, like "primes", used to select problems.
+	Tags []string
+	// DataFile is name of the file in data directory the problem depends on, if there is one.
+	DataFile string
+	// Limits overrides resource limits of worker running this problem.
+	Limits ResourceLimits
+	// Budgets are expected time cost of methods, runner uses them as method timeouts.
+	Budgets map[string]time.Duration
+	// Slow are methods known to take long, runner skips them unless slow methods are included.
+	Slow []string
+}
+
+func (p Problem) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Budget returns expected time cost of method, 0 if it is not declared.
+func (p Problem) Budget(method string) time.Duration {
+	return p.Budgets[method]
+}
+
+func (p Problem) IsSlow(method string) bool {
+	for _, m := range p.Slow {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (p Problem) GetDescription() string {
@@ -193,6 +351,7 @@ func (p Problem) runMethod(method string) *ResultItem {
 	item := &ResultItem{
 		ProblemId: p.Id,
 		Method:    method,
+		Status:    StatusOK,
 	}
 
 	start := time.Now()
@@ -236,6 +395,33 @@ func (p Problem) RunAll() *Result {
 	return result
 }
 
+// Verdict is the check result of a method.
+type Verdict string
+
+const (
+	VerdictCorrect  Verdict = "correct"
+	VerdictWrong    Verdict = "wrong"
+	VerdictUnknown  Verdict = "unknown"
+	VerdictNoResult Verdict = "no-result"
+)
+
+// Verdict checks result of a method with answer of the problem.
+func (p Problem) Verdict(item ResultItem) Verdict {
+	switch {
+	case !item.HasResult():
+		return VerdictNoResult
+
+	case p.NoAnswer:
+		return VerdictUnknown
+
+	case p.Answer.Equals(item.Result):
+		return VerdictCorrect
+
+	default:
+		return VerdictWrong
+	}
+}
+
 func (p Problem) Check(t *testing.T) TestContext {
 	ctx := TestContext{
 		t:        t,
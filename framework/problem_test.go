@@ -0,0 +1,110 @@
+package framework
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTB records failures and skips instead of failing or skipping the test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+	skips  []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Logf(format string, args ...interface{}) {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func (r *recordingTB) Skipf(format string, args ...interface{}) {
+	r.skips = append(r.skips, fmt.Sprintf(format, args...))
+}
+
+func TestTestContextLimits(t *testing.T) {
+	sink := make([][]byte, 0)
+	allocating := func() int64 {
+		sink = append(sink, make([]byte, 1<<20))
+		return 42
+	}
+
+	calls := 0
+	// The first run is slow, best of 3 runs is not.
+	noisy := func() int64 {
+		calls++
+		if calls == 1 {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		return 42
+	}
+
+	cases := []struct {
+		ctx      func(c TestContext) TestContext
+		solution Solution
+		errors   []string
+	}{
+		{func(c TestContext) TestContext { return c }, allocating, nil},
+		{func(c TestContext) TestContext { return c.MaxAllocBytes(1 << 10) }, allocating,
+			[]string{"allocated"}},
+		{func(c TestContext) TestContext { return c.MaxAllocBytes(4 << 20) }, allocating, nil},
+		{func(c TestContext) TestContext { return c.Within(10 * time.Millisecond).BestOf(1) }, noisy,
+			[]string{"best of 1 runs"}},
+		{func(c TestContext) TestContext { return c.Within(10 * time.Millisecond).BestOf(3) }, noisy, nil},
+		{func(c TestContext) TestContext { return c.Within(10 * time.Millisecond) }, noisy, nil},
+		{func(c TestContext) TestContext { return c }, func() int64 { return 41 }, []string{"wrong answer"}},
+	}
+
+	for i, c := range cases {
+		calls = 0
+		tb := &recordingTB{TB: t}
+		c.ctx(TestContext{t: tb, answer: 42}).On(c.solution, "method")
+		if len(tb.errors) != len(c.errors) {
+			t.Errorf("case %d: expected errors %v, got %v", i, c.errors, tb.errors)
+			continue
+		}
+
+		for j, e := range c.errors {
+			if !strings.Contains(tb.errors[j], e) {
+				t.Errorf("case %d: expected error with '%s', got '%s'", i, e, tb.errors[j])
+			}
+		}
+	}
+}
+
+func TestTestContextRuns(t *testing.T) {
+	calls := 0
+	counting := func() int64 {
+		calls++
+		return 42
+	}
+
+	cases := []struct {
+		ctx   func(c TestContext) TestContext
+		calls int
+	}{
+		{func(c TestContext) TestContext { return c }, 1},
+		{func(c TestContext) TestContext { return c.Within(time.Second) }, DefaultBestOf},
+		{func(c TestContext) TestContext { return c.MaxAllocBytes(1 << 20) }, DefaultBestOf},
+		{func(c TestContext) TestContext { return c.Within(time.Second).BestOf(5) }, 5},
+		{func(c TestContext) TestContext { return c.BestOf(2) }, 2},
+	}
+
+	for i, c := range cases {
+		calls = 0
+		tb := &recordingTB{TB: t}
+		c.ctx(TestContext{t: tb, answer: 42}).On(counting, "method")
+		if calls != c.calls || len(tb.errors) != 0 {
+			t.Errorf("case %d: expected %d runs without error, got %d runs, errors %v", i, c.calls, calls, tb.errors)
+		}
+	}
+}
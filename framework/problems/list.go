@@ -8,7 +8,6 @@ import (
 	"github.com/flily/projeuler.go/problems/p0023"
 	"github.com/flily/projeuler.go/problems/p0027"
 	"github.com/flily/projeuler.go/problems/p0039"
-	"github.com/flily/projeuler.go/problems/p0092"
 )
 
 var Problems = []Problem{
@@ -19,5 +18,4 @@ var Problems = []Problem{
 	p0023.Problem,
 	p0027.Problem,
 	p0039.Problem,
-	p0092.Problem,
 }
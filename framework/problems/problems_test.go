@@ -0,0 +1,26 @@
+package problems
+
+import (
+	"testing"
+
+	"github.com/flily/projeuler.go/framework"
+)
+
+func TestProblems(t *testing.T) {
+	framework.RunProblemTests(t, Problems)
+}
+
+func BenchmarkProblems(b *testing.B) {
+	framework.BenchmarkProblems(b, Problems)
+}
+
+func TestValidate(t *testing.T) {
+	violations, err := framework.ValidateProblems("../..")
+	if err != nil {
+		t.Fatalf("validate problems failed: %s", err)
+	}
+
+	for _, violation := range violations {
+		t.Error(violation)
+	}
+}
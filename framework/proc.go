@@ -0,0 +1,209 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// stderrTailSize is the size of stderr output of worker kept for diagnosis.
+const stderrTailSize = 4096
+
+type WorkerProc struct {
+	Proc   *os.Process
+	State  *os.ProcessState
+	Limits ResourceLimits
+	Stderr *TailBuffer
+	exited chan struct{}
+	copied chan struct{}
+}
+
+// NewWorkerProc watches a started worker process. The tail of its stderr output is kept if
+// stderr is not nil, and also copied to echo if echo is not nil.
+func NewWorkerProc(proc *os.Process, stderr io.ReadCloser, echo io.Writer) *WorkerProc {
+	if proc == nil {
+		return nil
+	}
+
+	w := &WorkerProc{
+		Proc:   proc,
+		Stderr: NewTailBuffer(stderrTailSize),
+		exited: make(chan struct{}),
+		copied: make(chan struct{}),
+	}
+
+	if stderr != nil {
+		var output io.Writer = w.Stderr
+		if echo != nil {
+			output = io.MultiWriter(w.Stderr, echo)
+		}
+
+		go func() {
+			_, _ = io.Copy(output, stderr)
+			_ = stderr.Close()
+			close(w.copied)
+		}()
+
+	} else {
+		close(w.copied)
+	}
+
+	go w.wait()
+	return w
+}
+
+func (w *WorkerProc) wait() {
+	state, _ := w.Proc.Wait()
+	w.State = state
+
+	// Stderr is closed when worker exits, wait for the last output copied.
+	select {
+	case <-w.copied:
+	case <-time.After(workerExitMargin):
+	}
+
+	close(w.exited)
+}
+
+func (w *WorkerProc) Pid() int {
+	return w.Proc.Pid
+}
+
+// Exited returns a channel closed after worker process exited, State is available after that.
+func (w *WorkerProc) Exited() <-chan struct{} {
+	return w.exited
+}
+
+func (w *WorkerProc) HasExited() bool {
+	select {
+	case <-w.exited:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// WaitReady waits worker to report its address through the readiness pipe r. The worker is
+// killed if it is not ready in timeout.
+func (w *WorkerProc) WaitReady(r io.Reader, timeout time.Duration) (string, error) {
+	type readyResult struct {
+		address string
+		err     error
+	}
+
+	ready := make(chan readyResult, 1)
+	go func() {
+		address, err := ReadReady(r)
+		ready <- readyResult{address, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-ready:
+		if errors.Is(result.err, ErrWorkerExited) {
+			select {
+			case <-w.exited:
+			case <-timer.C:
+			}
+
+			return "", fmt.Errorf("worker (PID=%d) exited before ready: %s", w.Pid(), w.ExitStatus())
+		}
+
+		return result.address, result.err
+
+	case <-timer.C:
+		w.Kill()
+		return "", fmt.Errorf("worker (PID=%d) is not ready in %s", w.Pid(), timeout)
+	}
+}
+
+func (w *WorkerProc) Kill() {
+	if w.HasExited() {
+		return
+	}
+
+	err := w.Proc.Kill()
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		fmt.Printf("stop worker (PID=%d) failed: %s", w.Proc.Pid, err)
+	}
+}
+
+// Stop asks worker to shut down through client, and waits it to exit. Worker is killed if it
+// does not exit in grace period.
+func (w *WorkerProc) Stop(client *Client, grace time.Duration) {
+	if w.HasExited() {
+		return
+	}
+
+	if client != nil {
+		if err := client.Shutdown(grace); err != nil {
+			log.Printf("send shutdown to worker (PID=%d) failed: %s", w.Proc.Pid, err)
+		}
+	}
+
+	timer := time.NewTimer(grace + workerExitMargin)
+	defer timer.Stop()
+
+	select {
+	case <-w.exited:
+		log.Printf("worker (PID=%d) exited: %s", w.Proc.Pid, w.State)
+
+	case <-timer.C:
+		log.Printf("worker (PID=%d) did not exit in %s, kill it", w.Proc.Pid, grace)
+		w.Kill()
+	}
+}
+
+// ExitStatus describes how worker process exited, with its exit code or the signal killed it,
+// and the fatal error printed if there is one.
+func (w *WorkerProc) ExitStatus() string {
+	if !w.HasExited() || w.State == nil {
+		return "still running"
+	}
+
+	status := w.State.String()
+	if fatal := w.Stderr.Fatal(); fatal != "" {
+		status += ", " + fatal
+	}
+
+	return status
+}
+
+// workerExitMargin is time for worker to flush results and exit after grace period.
+const workerExitMargin = 500 * time.Millisecond
+
+// LimitExceeded tells which resource limit is exceeded by an exited worker, it returns empty
+// status if worker is still running or exited for any other reason.
+func (w *WorkerProc) LimitExceeded() ResultStatus {
+	if !w.HasExited() || w.State == nil {
+		return ""
+	}
+
+	if w.Limits.CPUTime > 0 {
+		used := w.State.UserTime() + w.State.SystemTime()
+		if w.State.ExitCode() == WorkerExitCPULimit || used >= w.Limits.CPUTime {
+			return StatusCPULimit
+		}
+	}
+
+	if w.Limits.Memory > 0 && isOutOfMemory(w.Stderr.Fatal()) {
+		return StatusMemoryLimit
+	}
+
+	return ""
+}
+
+// isOutOfMemory tells whether a fatal error is caused by failed memory allocation, including
+// thread stacks which can not be allocated either under memory limit.
+func isOutOfMemory(fatal string) bool {
+	return strings.Contains(fatal, "out of memory") ||
+		strings.Contains(fatal, "cannot allocate memory") ||
+		strings.Contains(fatal, "pthread_create failed")
+}
@@ -0,0 +1,311 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProjectConfigFiles are names of project config file, looked up in current directory in order.
+var ProjectConfigFiles = []string{"projeuler.toml", "projeuler.json"}
+
+// Duration is a time.Duration written as string like "500ms" in config file.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration like \"500ms\" expected, got %s", data)
+	}
+
+	value, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(value)
+	return nil
+}
+
+// ProjectDefaults are defaults of command line flags. Flags given on command line override them.
+type ProjectDefaults struct {
+	ProblemTimeout *Duration `json:"problem_timeout,omitempty"`
+	MethodTimeout  *Duration `json:"method_timeout,omitempty"`
+	TotalTimeout   *Duration `json:"total_timeout,omitempty"`
+	Port           *int      `json:"port,omitempty"`
+	Socket         *string   `json:"socket,omitempty"`
+	Transport      *string   `json:"transport,omitempty"`
+	SpareWorkers   *int      `json:"spare_workers,omitempty"`
+	Isolate        *bool     `json:"isolate,omitempty"`
+	Check          *bool     `json:"check,omitempty"`
+	FailOn         *string   `json:"fail_on,omitempty"`
+	Format         *string   `json:"format,omitempty"`
+	Count          *int      `json:"count,omitempty"`
+	IncludeSlow    *bool     `json:"include_slow,omitempty"`
+}
+
+// FlagValue is a value of command line flag.
+type FlagValue struct {
+	Name  string
+	Value string
+}
+
+// FlagValues returns defaults set in config, as values of command line flags.
+func (d *ProjectDefaults) FlagValues() []FlagValue {
+	result := make([]FlagValue, 0)
+	add := func(name string, value string) {
+		result = append(result, FlagValue{Name: name, Value: value})
+	}
+
+	if d.ProblemTimeout != nil {
+		add("problem-timeout", d.ProblemTimeout.String())
+	}
+
+	if d.MethodTimeout != nil {
+		add("method-timeout", d.MethodTimeout.String())
+	}
+
+	if d.TotalTimeout != nil {
+		add("total-timeout", d.TotalTimeout.String())
+	}
+
+	if d.Port != nil {
+		add("port", strconv.Itoa(*d.Port))
+	}
+
+	if d.Socket != nil {
+		add("socket", *d.Socket)
+	}
+
+	if d.Transport != nil {
+		add("transport", *d.Transport)
+	}
+
+	if d.SpareWorkers != nil {
+		add("spare-workers", strconv.Itoa(*d.SpareWorkers))
+	}
+
+	if d.Isolate != nil {
+		add("isolate", strconv.FormatBool(*d.Isolate))
+	}
+
+	if d.Check != nil {
+		add("check", strconv.FormatBool(*d.Check))
+	}
+
+	if d.FailOn != nil {
+		add("fail-on", *d.FailOn)
+	}
+
+	if d.Format != nil {
+		add("format", *d.Format)
+	}
+
+	if d.Count != nil {
+		add("count", strconv.Itoa(*d.Count))
+	}
+
+	if d.IncludeSlow != nil {
+		add("include-slow", strconv.FormatBool(*d.IncludeSlow))
+	}
+
+	return result
+}
+
+// MethodConfig overrides settings of methods. Unset fields are inherited from problem, and then
+// from command line flags.
+type MethodConfig struct {
+	MethodTimeout *Duration `json:"method_timeout,omitempty"`
+	Skip          *bool     `json:"skip,omitempty"`
+	ExpectedSlow  *bool     `json:"expected_slow,omitempty"`
+	Repeat        *int      `json:"repeat,omitempty"`
+}
+
+// apply overrides settings, except method timeout and repeat if keepTimeout and keepRepeat are set.
+func (c *MethodConfig) apply(s *MethodSettings, keepTimeout bool, keepRepeat bool) {
+	if c.MethodTimeout != nil && !keepTimeout {
+		s.MethodTimeout = time.Duration(*c.MethodTimeout)
+	}
+
+	if c.Skip != nil {
+		s.Skip = *c.Skip
+	}
+
+	if c.ExpectedSlow != nil {
+		s.ExpectedSlow = *c.ExpectedSlow
+	}
+
+	if c.Repeat != nil && !keepRepeat {
+		s.Repeat = *c.Repeat
+	}
+}
+
+// ProblemConfig overrides settings of a problem, and of all its methods.
+type ProblemConfig struct {
+	ProblemTimeout *Duration `json:"problem_timeout,omitempty"`
+	MethodConfig
+	Methods map[string]*MethodConfig `json:"methods,omitempty"`
+}
+
+// ProjectConfig is the project config file, with defaults of flags and overrides of problems.
+type ProjectConfig struct {
+	File string `json:"-"`
+	ProjectDefaults
+	Problems map[int]*ProblemConfig `json:"problems,omitempty"`
+}
+
+// LoadProjectConfig loads config file in path, or the first of ProjectConfigFiles found in current
+// directory if path is empty. An empty config is returned if path is empty and no file is found.
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	if path == "" {
+		for _, name := range ProjectConfigFiles {
+			if _, err := os.Stat(name); err == nil {
+				path = name
+				break
+			}
+		}
+	}
+
+	if path == "" {
+		return &ProjectConfig{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := ParseProjectConfig(data, filepath.Ext(path) == ".toml")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	config.File = path
+	return config, nil
+}
+
+// ParseProjectConfig parses config in TOML or JSON. TOML is converted to JSON before decoding,
+// so both are checked the same way, and unknown keys are errors.
+func ParseProjectConfig(data []byte, isTOML bool) (*ProjectConfig, error) {
+	if isTOML {
+		table := make(map[string]interface{})
+		if err := toml.Unmarshal(data, &table); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if data, err = json.Marshal(table); err != nil {
+			return nil, err
+		}
+	}
+
+	config := &ProjectConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	return config, nil
+}
+
+// CheckProblems returns errors of problems and methods in config but not in problems.
+func (c *ProjectConfig) CheckProblems(problems []Problem) []error {
+	index := make(map[int]Problem, len(problems))
+	for _, problem := range problems {
+		index[problem.Id] = problem
+	}
+
+	ids := make([]int, 0, len(c.Problems))
+	for id := range c.Problems {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+	result := make([]error, 0)
+	for _, id := range ids {
+		problem, found := index[id]
+		if !found {
+			result = append(result, fmt.Errorf("%w: %d", ErrNoSuchProblem, id))
+			continue
+		}
+
+		if c.Problems[id] == nil {
+			continue
+		}
+
+		for method := range c.Problems[id].Methods {
+			if _, found := problem.Methods[method]; !found {
+				result = append(result, fmt.Errorf("%w: %d.%s", ErrNoSuchSolution, id, method))
+			}
+		}
+	}
+
+	return result
+}
+
+// MethodSettings are effective settings of running a method.
+type MethodSettings struct {
+	ProblemTimeout time.Duration
+	MethodTimeout  time.Duration
+	Skip           bool
+	// ExpectedSlow methods are skipped unless slow methods are included, and their timeouts are
+	// not failures.
+	ExpectedSlow bool
+	// Repeat is number of runs in bench.
+	Repeat int
+}
+
+// MethodSettings returns settings of method. Budget and slow marker declared in problem are
+// applied on flags, and then overrides of problem and method in project config. Timeouts given on
+// command line override both, and so does number of runs in bench.
+func (c *Configure) MethodSettings(p Problem, method string) MethodSettings {
+	s := MethodSettings{
+		ProblemTimeout: c.ProblemTimeout,
+		MethodTimeout:  c.MethodTimeout,
+		ExpectedSlow:   p.IsSlow(method),
+		Repeat:         c.BenchCount,
+	}
+
+	if budget := p.Budget(method); budget > 0 && !c.GivenFlags["method-timeout"] {
+		s.MethodTimeout = budget
+	}
+
+	if c.Project == nil {
+		return s
+	}
+
+	problem := c.Project.Problems[p.Id]
+	if problem == nil {
+		return s
+	}
+
+	if problem.ProblemTimeout != nil && !c.GivenFlags["problem-timeout"] {
+		s.ProblemTimeout = time.Duration(*problem.ProblemTimeout)
+	}
+
+	keepTimeout, keepRepeat := c.GivenFlags["method-timeout"], c.GivenFlags["count"]
+	problem.MethodConfig.apply(&s, keepTimeout, keepRepeat)
+	if m := problem.Methods[method]; m != nil {
+		m.apply(&s, keepTimeout, keepRepeat)
+	}
+
+	return s
+}
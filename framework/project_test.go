@@ -0,0 +1,169 @@
+package framework
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testProjectConfigTOML = `
+# defaults of flags
+method_timeout = "1s"
+fail_on = 'wrong,timeout'
+spare_workers = 2
+
+[problems.14]
+problem_timeout = "10s"
+method_timeout = "2s"
+expected_slow = true
+
+[problems.14.methods.naive]
+expected_slow = false # override problem
+skip = true
+
+[problems.23]
+methods."with-factor-sum-cache".repeat = 1_0
+`
+
+const testProjectConfigJSON = `{
+	"method_timeout": "1s",
+	"fail_on": "wrong,timeout",
+	"spare_workers": 2,
+	"problems": {
+		"14": {
+			"problem_timeout": "10s",
+			"method_timeout": "2s",
+			"expected_slow": true,
+			"methods": {"naive": {"expected_slow": false, "skip": true}}
+		},
+		"23": {"methods": {"with-factor-sum-cache": {"repeat": 10}}}
+	}
+}`
+
+func TestProjectConfigMethodSettings(t *testing.T) {
+	for _, isTOML := range []bool{true, false} {
+		data := testProjectConfigJSON
+		if isTOML {
+			data = testProjectConfigTOML
+		}
+
+		project, err := ParseProjectConfig([]byte(data), isTOML)
+		if err != nil {
+			t.Fatalf("parse config (toml=%v) failed: %s", isTOML, err)
+		}
+
+		values := make(map[string]string)
+		for _, value := range project.FlagValues() {
+			values[value.Name] = value.Value
+		}
+
+		if len(values) != 3 || values["method-timeout"] != "1s" || values["fail-on"] != "wrong,timeout" ||
+			values["spare-workers"] != "2" {
+			t.Errorf("wrong flag values (toml=%v): %v", isTOML, values)
+		}
+
+		conf := &Configure{
+			ProblemTimeout: 5 * time.Second,
+			MethodTimeout:  500 * time.Millisecond,
+			BenchCount:     5,
+			Project:        project,
+		}
+
+		cases := []struct {
+			problemId int
+			method    string
+			expected  MethodSettings
+		}{
+			{1, "naive", MethodSettings{5 * time.Second, 500 * time.Millisecond, false, false, 5}},
+			{14, "naive", MethodSettings{10 * time.Second, 2 * time.Second, true, false, 5}},
+			{14, "with-cache-map", MethodSettings{10 * time.Second, 2 * time.Second, false, true, 5}},
+			{23, "with-factor-sum-cache", MethodSettings{5 * time.Second, 3 * time.Second, false, false, 10}},
+		}
+
+		for _, c := range cases {
+			got := conf.MethodSettings(Problem{Id: c.problemId, Budgets: map[string]time.Duration{
+				"with-factor-sum-cache": 3 * time.Second,
+			}}, c.method)
+			if got != c.expected {
+				t.Errorf("settings of %d.%s (toml=%v): expected %+v, got %+v",
+					c.problemId, c.method, isTOML, c.expected, got)
+			}
+		}
+	}
+}
+
+func TestProjectConfigParseError(t *testing.T) {
+	cases := []struct {
+		data   string
+		isTOML bool
+		reason string
+	}{
+		{"a = 1\na = 2", true, "line 2"},
+		{"port = 1 2", true, "line 1"},
+		{"check = true\nport = x", true, "line 2"},
+		{"port = 1.5", true, "cannot unmarshal number 1.5"},
+		{"timeout = \"1s\"", true, `unknown field "timeout"`},
+		{`method_timeout = "1 s"`, true, "unknown unit"},
+		{`{"method_timeout": 1000}`, false, "duration like"},
+		{`{"problems": {"x": {}}}`, false, "cannot unmarshal"},
+	}
+
+	for _, c := range cases {
+		_, err := ParseProjectConfig([]byte(c.data), c.isTOML)
+		if err == nil || !strings.Contains(err.Error(), c.reason) {
+			t.Errorf("parse %q: expected error with %q, got %v", c.data, c.reason, err)
+		}
+	}
+}
+
+func TestProjectConfigCheckProblems(t *testing.T) {
+	data := `{"problems": {"2": {}, "14": {"methods": {"naive": {}, "fast": {}}}, "23": null}}`
+	project, err := ParseProjectConfig([]byte(data), false)
+	if err != nil {
+		t.Fatalf("parse config failed: %s", err)
+	}
+
+	errs := project.CheckProblems(makeSelectionTestProblems())
+	got := make([]string, 0, len(errs))
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+
+	expected := "no such problem: 2; no such solution: 14.fast"
+	if strings.Join(got, "; ") != expected {
+		t.Errorf("expected errors %q, got %q", expected, got)
+	}
+
+	problem := Problem{Id: 23, Slow: []string{"naive"}}
+	if settings := (&Configure{Project: project}).MethodSettings(problem, "naive"); settings.Skip ||
+		!settings.ExpectedSlow {
+		t.Errorf("null problem config should not change settings, got %+v", settings)
+	}
+}
+
+func TestProjectConfigKeepsGivenFlags(t *testing.T) {
+	project, err := ParseProjectConfig([]byte(testProjectConfigTOML), true)
+	if err != nil {
+		t.Fatalf("parse config failed: %s", err)
+	}
+
+	conf := &Configure{
+		ProblemTimeout: 5 * time.Second,
+		MethodTimeout:  500 * time.Millisecond,
+		BenchCount:     5,
+		Project:        project,
+		GivenFlags:     map[string]bool{"problem-timeout": true, "method-timeout": true, "count": true},
+	}
+
+	expected := MethodSettings{5 * time.Second, 500 * time.Millisecond, true, false, 5}
+	if got := conf.MethodSettings(Problem{Id: 14}, "naive"); got != expected {
+		t.Errorf("timeouts given on command line should be kept, expected %+v, got %+v", expected, got)
+	}
+
+	problem := Problem{Id: 23, Budgets: map[string]time.Duration{"with-factor-sum-cache": 3 * time.Second}}
+	expected = MethodSettings{5 * time.Second, 500 * time.Millisecond, false, false, 5}
+	if got := conf.MethodSettings(problem, "with-factor-sum-cache"); got != expected {
+		t.Errorf("method timeout and count given on command line should override budget and repeat, "+
+			"expected %+v, got %+v", expected, got)
+	}
+}
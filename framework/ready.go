@@ -0,0 +1,38 @@
+package framework
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadyFd is the file descriptor on which a worker started by runner reports its address, after
+// it is ready to accept connections.
+const ReadyFd = 3
+
+const readyPrefix = "ready "
+
+// WriteReady writes the readiness line of worker, with the address client should dial.
+func WriteReady(w io.Writer, address string) error {
+	_, err := fmt.Fprintf(w, "%s%s\n", readyPrefix, address)
+	return err
+}
+
+// ReadReady reads the readiness line written by WriteReady, and returns the address in it.
+func ReadReady(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			return "", ErrWorkerExited
+		}
+
+		return "", err
+	}
+
+	if !strings.HasPrefix(line, readyPrefix) {
+		return "", fmt.Errorf("invalid readiness line: %q", line)
+	}
+
+	return strings.TrimSpace(line[len(readyPrefix):]), nil
+}
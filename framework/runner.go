@@ -9,12 +9,15 @@ import (
 func emptyCancel() {}
 
 func NewTimeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
-	background := context.Background()
+	return NewTimeoutContextFrom(context.Background(), timeout)
+}
+
+func NewTimeoutContextFrom(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 	if timeout <= 0 {
-		return background, emptyCancel
+		return parent, emptyCancel
 	}
 
-	return context.WithTimeout(background, timeout)
+	return context.WithTimeout(parent, timeout)
 }
 
 type resultPackage struct {
@@ -50,14 +53,19 @@ func (r *Runner) Import(problems []Problem) {
 func (r *Runner) RunProblem(info ProblemRunInfo) (*Result, error) {
 	problem, found := r.Index[info.ProblemId]
 	if !found {
-		return nil, fmt.Errorf("no problem %d", info.ProblemId)
+		return nil, fmt.Errorf("%w: %d", ErrNoSuchProblem, info.ProblemId)
 	}
 
 	if info.IsAllMethods() {
 		return problem.RunAll(), nil
-	} else {
-		return problem.RunMethod(info.Method), nil
 	}
+
+	result := problem.RunMethod(info.Method)
+	if result == nil {
+		return nil, fmt.Errorf("%w: %d '%s'", ErrNoSuchSolution, info.ProblemId, info.Method)
+	}
+
+	return result, nil
 }
 
 func (r *Runner) runProblemWrap(ch chan<- resultPackage, info ProblemRunInfo) {
@@ -0,0 +1,318 @@
+package framework
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Predicates of problems and methods in selection.
+const (
+	PredicateAll      = "all"
+	PredicateUnsolved = "unsolved"
+	PredicateNoAnswer = "noanswer"
+	PredicateSlow     = "slow"
+	PredicateTag      = "tag:"
+)
+
+// ProblemState tells what is known about problems from previous runs.
+type ProblemState interface {
+	// Solved tells whether any method of problem has been recorded with the correct answer.
+	Solved(problem Problem) bool
+	// Slow tells whether a method is known to be slow.
+	Slow(problem Problem, method string) bool
+}
+
+// SelectionError is an error in selection expression, pointing at the offending token.
+type SelectionError struct {
+	Arg    string
+	Index  int
+	Offset int
+	Reason string
+}
+
+func (e *SelectionError) Error() string {
+	return fmt.Sprintf("invalid selection '%s' at argument %d: %s\n  %s\n  %s^",
+		e.Arg, e.Index+1, e.Reason, e.Arg, strings.Repeat(" ", e.Offset))
+}
+
+type selectionTerm struct {
+	exclude   bool
+	predicate string
+	tag       string
+	first     int
+	last      int
+	method    string
+	explicit  bool
+	arg       int
+	offset    int
+	sourceArg string
+}
+
+func (t *selectionTerm) matchProblem(problem Problem, state ProblemState) bool {
+	switch t.predicate {
+	case "":
+		return problem.Id >= t.first && problem.Id <= t.last
+
+	case PredicateAll, PredicateSlow:
+		return true
+
+	case PredicateUnsolved:
+		return state == nil || !state.Solved(problem)
+
+	case PredicateNoAnswer:
+		return problem.NoAnswer
+
+	case PredicateTag:
+		return problem.HasTag(t.tag)
+
+	default:
+		return false
+	}
+}
+
+func (t *selectionTerm) matchMethod(problem Problem, method string, state ProblemState) bool {
+	if t.predicate == PredicateSlow {
+		return state != nil && state.Slow(problem, method)
+	}
+
+	if t.method == "" {
+		return true
+	}
+
+	matched, _ := path.Match(t.method, method)
+	return matched
+}
+
+func (t *selectionTerm) errorf(format string, args ...interface{}) error {
+	return &SelectionError{
+		Arg:    t.sourceArg,
+		Index:  t.arg,
+		Offset: t.offset,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// Selection selects methods of problems to run. It is a list of arguments, each argument is a
+// comma separated list of items:
+//
+//	14          all methods of problem 14
+//	1-50        all methods of problems 1 to 50
+//	14.naive    method naive of problem 14
+//	14.with-*   methods of problem 14 matching the glob
+//	unsolved    problems never recorded with the correct answer
+//	noanswer    problems without known answer
+//	slow        methods known to be slow
+//	tag:primes  problems with tag primes
+//	!23.naive   excludes the item, all problems are selected if there are only exclusions
+type Selection struct {
+	terms []*selectionTerm
+}
+
+// ParseSelection parses selection from command line arguments. Empty selection selects all.
+func ParseSelection(args []string) (*Selection, error) {
+	s := &Selection{
+		terms: make([]*selectionTerm, 0, len(args)),
+	}
+
+	for i, arg := range args {
+		offset := 0
+		for _, item := range strings.Split(arg, ",") {
+			term := &selectionTerm{
+				arg:       i,
+				offset:    offset,
+				sourceArg: arg,
+			}
+
+			if err := parseSelectionItem(term, item); err != nil {
+				return nil, err
+			}
+
+			s.terms = append(s.terms, term)
+			offset += len(item) + 1
+		}
+	}
+
+	return s, nil
+}
+
+func parseSelectionItem(term *selectionTerm, item string) error {
+	if strings.HasPrefix(item, "!") {
+		term.exclude = true
+		term.offset++
+		item = item[1:]
+	}
+
+	if item == "" {
+		return term.errorf("empty item")
+	}
+
+	switch {
+	case item == PredicateAll, item == PredicateUnsolved, item == PredicateNoAnswer, item == PredicateSlow:
+		term.predicate = item
+		return nil
+
+	case strings.HasPrefix(item, PredicateTag):
+		term.predicate = PredicateTag
+		term.tag = item[len(PredicateTag):]
+		if term.tag == "" {
+			term.offset += len(PredicateTag)
+			return term.errorf("tag name expected")
+		}
+
+		return nil
+	}
+
+	ids := item
+	if dot := strings.Index(item, "."); dot >= 0 {
+		ids, term.method = item[:dot], item[dot+1:]
+		if term.method == "" {
+			term.offset += dot + 1
+			return term.errorf("method name expected after '.'")
+		}
+
+		if _, err := path.Match(term.method, ""); err != nil {
+			term.offset += dot + 1
+			return term.errorf("invalid method pattern '%s'", term.method)
+		}
+
+		term.explicit = !strings.ContainsAny(term.method, "*?[")
+	}
+
+	first, last := ids, ids
+	if dash := strings.Index(ids, "-"); dash >= 0 {
+		first, last = ids[:dash], ids[dash+1:]
+	}
+
+	var err error
+	if term.first, err = strconv.Atoi(first); err != nil || term.first <= 0 {
+		return term.errorf("problem id or predicate expected, got '%s'", first)
+	}
+
+	if term.last, err = strconv.Atoi(last); err != nil || term.last <= 0 {
+		term.offset += len(first) + 1
+		return term.errorf("problem id expected after '-', got '%s'", last)
+	}
+
+	if term.last < term.first {
+		return term.errorf("range %d-%d is empty", term.first, term.last)
+	}
+
+	return nil
+}
+
+// ProblemSelection is a problem with its selected methods.
+type ProblemSelection struct {
+	Problem Problem
+	Methods []string
+}
+
+// RunInfoList returns a run info for each selected method.
+func (s ProblemSelection) RunInfoList() []ProblemRunInfo {
+	result := make([]ProblemRunInfo, 0, len(s.Methods))
+	for _, method := range s.Methods {
+		result = append(result, NewProblemRunInfo(s.Problem.Id, method))
+	}
+
+	return result
+}
+
+// Resolve returns selected problems in order of problems, with methods sorted by name. It is an
+// error if a single problem id or a method name without glob is given but not found.
+func (s *Selection) Resolve(problems []Problem, state ProblemState) ([]ProblemSelection, error) {
+	if err := s.check(problems); err != nil {
+		return nil, err
+	}
+
+	includeAll := true
+	for _, term := range s.terms {
+		if !term.exclude {
+			includeAll = false
+			break
+		}
+	}
+
+	result := make([]ProblemSelection, 0)
+	for _, problem := range problems {
+		methods := make([]string, 0, len(problem.Methods))
+		for _, method := range problem.MethodList() {
+			if s.selected(problem, method, state, includeAll) {
+				methods = append(methods, method)
+			}
+		}
+
+		if len(methods) > 0 {
+			result = append(result, ProblemSelection{
+				Problem: problem,
+				Methods: methods,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Selection) selected(problem Problem, method string, state ProblemState, includeAll bool) bool {
+	selected := includeAll
+	for _, term := range s.terms {
+		if term.exclude || selected {
+			continue
+		}
+
+		selected = term.matchProblem(problem, state) && term.matchMethod(problem, method, state)
+	}
+
+	if !selected {
+		return false
+	}
+
+	for _, term := range s.terms {
+		if term.exclude && term.matchProblem(problem, state) && term.matchMethod(problem, method, state) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Explicit tells whether a selected method is asked for by its name without glob, or by the slow
+// predicate, rather than by problems, globs or other predicates.
+func (s *Selection) Explicit(problem Problem, method string) bool {
+	for _, term := range s.terms {
+		if term.exclude {
+			continue
+		}
+
+		if term.predicate == PredicateSlow ||
+			term.explicit && term.matchProblem(problem, nil) && term.method == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Selection) check(problems []Problem) error {
+	index := make(map[int]Problem, len(problems))
+	for _, problem := range problems {
+		index[problem.Id] = problem
+	}
+
+	for _, term := range s.terms {
+		if term.predicate != "" || term.first != term.last {
+			continue
+		}
+
+		problem, found := index[term.first]
+		if !found {
+			return term.errorf("%s: %d", ErrNoSuchProblem, term.first)
+		}
+
+		if _, found := problem.Methods[term.method]; term.explicit && !found {
+			return term.errorf("%s: %d.%s", ErrNoSuchSolution, term.first, term.method)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func makeSelectionTestProblems() []Problem {
+	solution := func() int64 { return 0 }
+	return []Problem{
+		{Id: 1, Answer: 1, Tags: []string{"arithmetic"}, Methods: map[string]Solution{"naive": solution}},
+		{Id: 10, Answer: 10, Tags: []string{"primes"}, Methods: map[string]Solution{"naive": solution}},
+		{Id: 14, Answer: 14, Methods: map[string]Solution{
+			"naive":           solution,
+			"with-cache-list": solution,
+			"with-cache-map":  solution,
+		}},
+		{Id: 23, Answer: 23, Methods: map[string]Solution{
+			"naive":                 solution,
+			"with-factor-sum-cache": solution,
+		}},
+		{Id: 99, NoAnswer: true, Tags: []string{"primes"}, Methods: map[string]Solution{"naive": solution}},
+	}
+}
+
+type testProblemState struct{}
+
+func (testProblemState) Solved(problem Problem) bool {
+	return problem.Id < 14
+}
+
+func (testProblemState) Slow(problem Problem, method string) bool {
+	return method == "naive" && problem.Id >= 14
+}
+
+func resolveToStrings(t *testing.T, args ...string) []string {
+	selection, err := ParseSelection(args)
+	if err != nil {
+		t.Fatalf("parse %v failed: %s", args, err)
+	}
+
+	selected, err := selection.Resolve(makeSelectionTestProblems(), testProblemState{})
+	if err != nil {
+		t.Fatalf("resolve %v failed: %s", args, err)
+	}
+
+	result := make([]string, 0)
+	for _, s := range selected {
+		for _, method := range s.Methods {
+			result = append(result, fmt.Sprintf("%d.%s", s.Problem.Id, method))
+		}
+	}
+
+	return result
+}
+
+func TestSelectionResolve(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected []string
+	}{
+		{nil, []string{"1.naive", "10.naive", "14.naive", "14.with-cache-list", "14.with-cache-map",
+			"23.naive", "23.with-factor-sum-cache", "99.naive"}},
+		{[]string{"1-13"}, []string{"1.naive", "10.naive"}},
+		{[]string{"23,1"}, []string{"1.naive", "23.naive", "23.with-factor-sum-cache"}},
+		{[]string{"14.with-*"}, []string{"14.with-cache-list", "14.with-cache-map"}},
+		{[]string{"14", "!14.naive"}, []string{"14.with-cache-list", "14.with-cache-map"}},
+		{[]string{"!14", "!23", "!1-10"}, []string{"99.naive"}},
+		{[]string{"unsolved", "!slow"}, []string{"14.with-cache-list", "14.with-cache-map",
+			"23.with-factor-sum-cache"}},
+		{[]string{"noanswer"}, []string{"99.naive"}},
+		{[]string{"tag:primes"}, []string{"10.naive", "99.naive"}},
+		{[]string{"slow", "!99"}, []string{"14.naive", "23.naive"}},
+	}
+
+	for _, c := range cases {
+		got := resolveToStrings(t, c.args...)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("selection %v:\nexpected %v\n     got %v", c.args, c.expected, got)
+		}
+	}
+}
+
+func TestSelectionParseError(t *testing.T) {
+	cases := []struct {
+		args   []string
+		index  int
+		offset int
+	}{
+		{[]string{"14", "x"}, 1, 0},
+		{[]string{"1-"}, 0, 2},
+		{[]string{"1,5-b"}, 0, 4},
+		{[]string{"!"}, 0, 1},
+		{[]string{"14."}, 0, 3},
+		{[]string{"tag:"}, 0, 4},
+		{[]string{"20-10"}, 0, 0},
+		{[]string{"14.[a"}, 0, 3},
+	}
+
+	for _, c := range cases {
+		_, err := ParseSelection(c.args)
+		selectionErr := &SelectionError{}
+		if !errors.As(err, &selectionErr) {
+			t.Errorf("selection %v: expected SelectionError, got %v", c.args, err)
+			continue
+		}
+
+		if selectionErr.Index != c.index || selectionErr.Offset != c.offset {
+			t.Errorf("selection %v: expected error at %d:%d, got %d:%d",
+				c.args, c.index, c.offset, selectionErr.Index, selectionErr.Offset)
+		}
+
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != 3 || strings.Index(lines[2], "^") != c.offset+2 {
+			t.Errorf("selection %v: caret misplaced in error:\n%s", c.args, err)
+		}
+	}
+}
+
+func TestSelectionResolveNotFound(t *testing.T) {
+	for _, arg := range []string{"2", "14.fast", "!14.fast"} {
+		selection, err := ParseSelection([]string{arg})
+		if err != nil {
+			t.Fatalf("parse %s failed: %s", arg, err)
+		}
+
+		if _, err := selection.Resolve(makeSelectionTestProblems(), nil); err == nil {
+			t.Errorf("resolve %s should fail", arg)
+		}
+	}
+}
+
+func TestSelectionExplicit(t *testing.T) {
+	cases := []struct {
+		args     []string
+		method   string
+		expected bool
+	}{
+		{[]string{"14"}, "naive", false},
+		{[]string{"14.naive"}, "naive", true},
+		{[]string{"14.naive"}, "with-cache-map", false},
+		{[]string{"14.with-*"}, "with-cache-map", false},
+		{[]string{"1-20.naive"}, "naive", true},
+		{[]string{"slow"}, "naive", true},
+		{[]string{"!14.naive"}, "naive", false},
+	}
+
+	problem := makeSelectionTestProblems()[2]
+	for _, c := range cases {
+		selection, err := ParseSelection(c.args)
+		if err != nil {
+			t.Fatalf("parse %v failed: %s", c.args, err)
+		}
+
+		if got := selection.Explicit(problem, c.method); got != c.expected {
+			t.Errorf("selection %v explicit %s: expected %v, got %v", c.args, c.method, c.expected, got)
+		}
+	}
+}
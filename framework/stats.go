@@ -0,0 +1,118 @@
+package framework
+
+import (
+	"math"
+	"sort"
+)
+
+// maxExactSamples is the max number of samples on each side, with which p-value of Mann-Whitney
+// U test is computed exactly. Normal approximation is used on more samples, or on ties.
+const maxExactSamples = 30
+
+// MannWhitneyU tests whether samples x and y come from the same distribution, without assuming
+// it is normal, which time costs seldom are. It returns U of x, the number of pairs in which x
+// is greater than y, and the two-sided p-value.
+func MannWhitneyU(x, y []float64) (float64, float64) {
+	n, m := len(x), len(y)
+	if n == 0 || m == 0 {
+		return 0, 1
+	}
+
+	type sample struct {
+		value float64
+		fromX bool
+	}
+
+	all := make([]sample, 0, n+m)
+	for _, v := range x {
+		all = append(all, sample{v, true})
+	}
+
+	for _, v := range y {
+		all = append(all, sample{v, false})
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Ranks start from 1, tied samples share the average of their ranks.
+	rankSumX, tieTerm := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromX {
+				rankSumX += rank
+			}
+		}
+
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	u := rankSumX - float64(n*(n+1))/2
+	if tieTerm == 0 && n <= maxExactSamples && m <= maxExactSamples {
+		return u, exactMannWhitneyP(n, m, int(u))
+	}
+
+	nf, mf := float64(n), float64(m)
+	mean := nf * mf / 2
+	variance := nf * mf / 12 * (nf + mf + 1 - tieTerm/((nf+mf)*(nf+mf-1)))
+	if variance <= 0 {
+		return u, 1
+	}
+
+	// Continuity correction moves U half a step towards the mean.
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+
+	return u, math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// exactMannWhitneyP returns two-sided p-value of U, from the exact distribution of U for n and m
+// samples without ties.
+func exactMannWhitneyP(n, m int, u int) float64 {
+	// counts[i][j][k] is the number of orders of i samples of x and j samples of y, in which U is
+	// k. The greatest sample is either from x, greater than all j samples of y, or from y.
+	counts := make([][][]float64, n+1)
+	for i := 0; i <= n; i++ {
+		counts[i] = make([][]float64, m+1)
+		for j := 0; j <= m; j++ {
+			counts[i][j] = make([]float64, i*j+1)
+			if i == 0 || j == 0 {
+				counts[i][j][0] = 1
+				continue
+			}
+
+			for k := range counts[i][j] {
+				if k >= j && k-j < len(counts[i-1][j]) {
+					counts[i][j][k] += counts[i-1][j][k-j]
+				}
+
+				if k < len(counts[i][j-1]) {
+					counts[i][j][k] += counts[i][j-1][k]
+				}
+			}
+		}
+	}
+
+	total, lower, upper := 0.0, 0.0, 0.0
+	for k, c := range counts[n][m] {
+		total += c
+		if k <= u {
+			lower += c
+		}
+
+		if k >= u {
+			upper += c
+		}
+	}
+
+	return math.Min(1, 2*math.Min(lower, upper)/total)
+}
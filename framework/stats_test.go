@@ -0,0 +1,47 @@
+package framework
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	cases := []struct {
+		x, y []float64
+		u, p float64
+	}{
+		// Completely separated, the most extreme of C(10, 5) = 252 orders on either side.
+		{[]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 0, 2.0 / 252},
+		{[]float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5}, 25, 2.0 / 252},
+		// Interleaved evenly, U is at the mean.
+		{[]float64{1, 4, 5, 8}, []float64{2, 3, 6, 7}, 8, 1},
+		{[]float64{1, 2}, []float64{3}, 0, 2.0 / 3},
+		{[]float64{1}, []float64{}, 0, 1},
+	}
+
+	for _, c := range cases {
+		u, p := MannWhitneyU(c.x, c.y)
+		if u != c.u || math.Abs(p-c.p) > 1e-9 {
+			t.Errorf("U test of %v and %v: expected U=%g p=%g, got U=%g p=%g", c.x, c.y, c.u, c.p, u, p)
+		}
+	}
+}
+
+func TestMannWhitneyUApproximation(t *testing.T) {
+	x := make([]float64, 0, 40)
+	y := make([]float64, 0, 40)
+	for i := 0; i < 40; i++ {
+		x = append(x, float64(i))
+		y = append(y, float64(i)+20)
+	}
+
+	u, p := MannWhitneyU(x, y)
+	if u != 200 || p > 1e-4 {
+		t.Errorf("shifted samples should differ significantly, got U=%g p=%g", u, p)
+	}
+
+	// Ties fall back to normal approximation, identical samples never differ.
+	if _, p := MannWhitneyU([]float64{1, 1, 2, 2}, []float64{1, 1, 2, 2}); p != 1 {
+		t.Errorf("identical samples should have p=1, got %g", p)
+	}
+}
@@ -0,0 +1,135 @@
+package framework
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// DefaultTestTimeout is timeout of methods without budget in tests.
+const DefaultTestTimeout = 10 * time.Second
+
+// RunProblemTests tests every method of problems, each in a subtest named like "p0014/naive".
+func RunProblemTests(t *testing.T, problems []Problem) {
+	for _, problem := range problems {
+		t.Run(fmt.Sprintf("p%04d", problem.Id), problem.RunTests)
+	}
+}
+
+// RunTests tests every method of problem in a subtest, checking its answer. Methods time out
+// after their budgets, and slow methods are skipped in short mode.
+func (p Problem) RunTests(t *testing.T) {
+	for _, method := range p.MethodList() {
+		method := method
+		t.Run(method, func(t *testing.T) {
+			p.testMethod(t, method, testing.Short())
+		})
+	}
+}
+
+// testMethod tests a method of problem, it returns after t.Fatalf or t.Skipf, which do not stop
+// the goroutine if t is not a *testing.T.
+func (p Problem) testMethod(t testing.TB, method string, short bool) {
+	if short && p.IsSlow(method) {
+		t.Skipf("method '%s' is slow, skipped in short mode", method)
+		return
+	}
+
+	timeout := p.Budget(method)
+	if timeout <= 0 {
+		timeout = DefaultTestTimeout
+	}
+
+	item := callWithTimeout(p.Methods[method], timeout)
+	if item.Status == StatusTimeout {
+		t.Fatalf("Method '%s' timeout after %s", method, timeout)
+		return
+
+	} else if item.Status == StatusCrashed {
+		t.Fatalf("Method '%s' panicked: %s", method, item.Stderr)
+		return
+	}
+
+	switch p.Verdict(item) {
+	case VerdictWrong:
+		t.Errorf("Got wrong answer '%d' of method '%s', expect %d", item.Result, method, p.Answer)
+
+	case VerdictUnknown:
+		t.Logf("method '%s': %d", method, item.Result)
+	}
+
+	t.Logf("method '%s' finished in %s", method, item.TimeCost)
+}
+
+// callWithTimeout calls solution in a goroutine, which is left running if it does not return
+// in timeout. Status of result is StatusCrashed with the panic in Stderr if solution panics.
+func callWithTimeout(solution Solution, timeout time.Duration) ResultItem {
+	done := make(chan ResultItem, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- ResultItem{Status: StatusCrashed, Stderr: fmt.Sprint(r), TimeCost: time.Since(start)}
+			}
+		}()
+
+		result := solution()
+		done <- ResultItem{Status: StatusOK, Result: result, TimeCost: time.Since(start)}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case item := <-done:
+		return item
+
+	case <-timer.C:
+		return ResultItem{Status: StatusTimeout, IsTimeout: true, TimeCost: timeout}
+	}
+}
+
+// BenchmarkProblems benchmarks every method of problems, each in a sub-benchmark named like
+// "p0014/naive".
+func BenchmarkProblems(b *testing.B, problems []Problem) {
+	for _, problem := range problems {
+		problem := problem
+		b.Run(fmt.Sprintf("p%04d", problem.Id), func(b *testing.B) {
+			Benchmark(b, problem)
+		})
+	}
+}
+
+// Benchmark benchmarks every method of problem in a sub-benchmark, reporting allocations, and
+// time cost in percent of the budget of method as metric "%budget" if it is declared. Slow
+// methods are skipped in short mode, and benchmark fails on wrong answers.
+func Benchmark(b *testing.B, p Problem) {
+	for _, method := range p.MethodList() {
+		method := method
+		solution := p.Methods[method]
+		b.Run(method, func(b *testing.B) {
+			if testing.Short() && p.IsSlow(method) {
+				b.Skipf("method '%s' is slow, skipped in short mode", method)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			var result int64
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				result = solution()
+			}
+
+			elapsed := time.Since(start)
+			b.StopTimer()
+
+			item := ResultItem{Status: StatusOK, Result: result}
+			if p.Verdict(item) == VerdictWrong {
+				b.Errorf("Got wrong answer '%d' of method '%s', expect %d", result, method, p.Answer)
+			}
+
+			if budget := p.Budget(method); budget > 0 {
+				b.ReportMetric(float64(elapsed)/float64(b.N)/float64(budget)*100, "%budget")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package framework
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallWithTimeout(t *testing.T) {
+	item := callWithTimeout(func() int64 { return 42 }, time.Second)
+	if item.Status != StatusOK || item.Result != 42 {
+		t.Errorf("expected result 42, got %+v", item)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	item = callWithTimeout(func() int64 { <-release; return 0 }, 10*time.Millisecond)
+	if item.Status != StatusTimeout || !item.IsTimeout {
+		t.Errorf("expected timeout, got %+v", item)
+	}
+
+	item = callWithTimeout(func() int64 { panic("boom") }, time.Second)
+	if item.Status != StatusCrashed || item.Stderr != "boom" {
+		t.Errorf("expected crash with 'boom', got %+v", item)
+	}
+}
+
+func TestRunTests(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	problem := Problem{
+		Id:     1,
+		Answer: 42,
+		Methods: map[string]Solution{
+			"fast":  func() int64 { return 42 },
+			"wrong": func() int64 { return 41 },
+			"slow":  func() int64 { <-release; return 42 },
+			"crash": func() int64 { panic("boom") },
+		},
+		Budgets: map[string]time.Duration{"slow": 10 * time.Millisecond},
+		Slow:    []string{"slow"},
+	}
+
+	cases := []struct {
+		method string
+		short  bool
+		errors []string
+		skips  []string
+	}{
+		{"fast", false, nil, nil},
+		{"fast", true, nil, nil},
+		{"wrong", false, []string{"wrong answer '41'"}, nil},
+		{"slow", true, nil, []string{"skipped in short mode"}},
+		{"slow", false, []string{"timeout after 10ms"}, nil},
+		{"crash", false, []string{"panicked: boom"}, nil},
+	}
+
+	for _, c := range cases {
+		tb := &recordingTB{TB: t}
+		problem.testMethod(tb, c.method, c.short)
+		if len(tb.errors) != len(c.errors) || len(tb.skips) != len(c.skips) {
+			t.Errorf("method '%s' (short=%v): expected errors %v and skips %v, got %v and %v",
+				c.method, c.short, c.errors, c.skips, tb.errors, tb.skips)
+			continue
+		}
+
+		for i, e := range c.errors {
+			if !strings.Contains(tb.errors[i], e) {
+				t.Errorf("method '%s': expected error with '%s', got '%s'", c.method, e, tb.errors[i])
+			}
+		}
+
+		for i, e := range c.skips {
+			if !strings.Contains(tb.skips[i], e) {
+				t.Errorf("method '%s': expected skip with '%s', got '%s'", c.method, e, tb.skips[i])
+			}
+		}
+	}
+
+	// Answer is not known, any result passes.
+	unknown := Problem{Id: 2, NoAnswer: true, Methods: map[string]Solution{"any": func() int64 { return 7 }}}
+	tb := &recordingTB{TB: t}
+	unknown.testMethod(tb, "any", false)
+	if len(tb.errors) != 0 {
+		t.Errorf("method without answer should pass, got %v", tb.errors)
+	}
+}
+
+func BenchmarkSuite(b *testing.B) {
+	problem := Problem{
+		Id:      1,
+		Answer:  42,
+		Methods: map[string]Solution{"fast": func() int64 { return 42 }},
+		Budgets: map[string]time.Duration{"fast": time.Millisecond},
+	}
+
+	Benchmark(b, problem)
+}
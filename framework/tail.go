@@ -0,0 +1,84 @@
+package framework
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// fatalPrefixes are beginnings of lines printed by Go runtime when a process crashes.
+var fatalPrefixes = []string{
+	"fatal error:",
+	"panic:",
+	"runtime:",
+	"runtime/cgo:",
+}
+
+// TailBuffer is a writer keeping only the last bytes written to it. The first fatal error line
+// is also kept, since it is usually followed by long stack traces.
+type TailBuffer struct {
+	lock  sync.Mutex
+	size  int
+	data  []byte
+	line  []byte
+	fatal string
+}
+
+func NewTailBuffer(size int) *TailBuffer {
+	b := &TailBuffer{
+		size: size,
+		data: make([]byte, 0, size),
+	}
+
+	return b
+}
+
+func (b *TailBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.scanFatal(p)
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.size; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+	}
+
+	return len(p), nil
+}
+
+func (b *TailBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return string(b.data)
+}
+
+// Fatal returns the first fatal error line written, or empty string if there is none.
+func (b *TailBuffer) Fatal() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.fatal
+}
+
+func (b *TailBuffer) scanFatal(p []byte) {
+	for b.fatal == "" && len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			if len(b.line) < b.size {
+				b.line = append(b.line, p...)
+			}
+
+			return
+		}
+
+		line := string(append(b.line, p[:i]...))
+		b.line = b.line[:0]
+		p = p[i+1:]
+
+		for _, prefix := range fatalPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				b.fatal = strings.TrimSpace(line)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Paths of problems, relative to root of the repository.
+const (
+	ProblemsDir      = "problems"
+	ProblemsListFile = "framework/problems/list.go"
+)
+
+var (
+	problemDirPattern  = regexp.MustCompile(`^p(\d{4})$`)
+	methodNamePattern  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	problemImportMatch = regexp.MustCompile(`/problems/(p\d{4})$`)
+)
+
+// Violation is a problem package breaking conventions, at a position of its source.
+type Violation struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s:%d: %s", v.File, v.Line, v.Message)
+}
+
+type validator struct {
+	root string
+	fset *token.FileSet
+	// suite tells whether methods of registered packages are all tested by RunProblemTests.
+	suite      bool
+	violations []Violation
+}
+
+func (v *validator) report(pos token.Pos, format string, args ...interface{}) {
+	position := v.fset.Position(pos)
+	v.reportAt(position.Filename, position.Line, format, args...)
+}
+
+func (v *validator) reportAt(file string, line int, format string, args ...interface{}) {
+	if rel, err := filepath.Rel(v.root, file); err == nil {
+		file = rel
+	}
+
+	v.violations = append(v.violations, Violation{
+		File:    filepath.ToSlash(file),
+		Line:    line,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) exists(path ...string) bool {
+	_, err := os.Stat(filepath.Join(append([]string{v.root}, path...)...))
+	return err == nil
+}
+
+// ValidateProblems checks conventions of problem packages in repository at root, sorted by
+// file and line: packages registered in list of problems exist and every package is registered,
+// Id matches directory, Description is not empty, data file exists, method names are
+// kebab-case, and every method is tested. Methods of registered packages are tested if a test
+// of list of problems calls RunProblemTests, others must be referenced in a test of their
+// package. Violations of a whole package are reported at its package clause, or at the directory
+// with line 0 if it has no Go file.
+func ValidateProblems(root string) ([]Violation, error) {
+	v := &validator{
+		root: root,
+		fset: token.NewFileSet(),
+	}
+
+	registered, err := v.checkList()
+	if err != nil {
+		return nil, err
+	}
+
+	if v.suite, err = v.hasSuite(); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, ProblemsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !problemDirPattern.MatchString(entry.Name()) {
+			continue
+		}
+
+		if err := v.checkPackage(entry.Name(), registered[entry.Name()]); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		if v.violations[i].File != v.violations[j].File {
+			return v.violations[i].File < v.violations[j].File
+		}
+
+		return v.violations[i].Line < v.violations[j].Line
+	})
+
+	return v.violations, nil
+}
+
+// checkList checks packages imported by list of problems exist, and returns the imported ones.
+func (v *validator) checkList() (map[string]bool, error) {
+	file, err := parser.ParseFile(v.fset, filepath.Join(v.root, ProblemsListFile), nil, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make(map[string]bool)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		match := problemImportMatch.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+
+		registered[match[1]] = true
+		if !v.exists(ProblemsDir, match[1]) {
+			v.report(spec.Pos(), "imported package %s does not exist in %s/", path, ProblemsDir)
+		}
+	}
+
+	return registered, nil
+}
+
+// hasSuite tells whether a test in directory of list of problems calls RunProblemTests.
+func (v *validator) hasSuite() (bool, error) {
+	dir := filepath.Dir(filepath.Join(v.root, ProblemsListFile))
+	pkg, err := v.parsePackage(dir, "problems")
+	if err != nil {
+		return false, err
+	}
+
+	return testedNames(pkg)["RunProblemTests"], nil
+}
+
+// packagePos returns position to report violations of whole package in dir at, which is package
+// clause of info.go, or of the first Go file if there is no info.go.
+func (v *validator) packagePos(dir string) (string, int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return dir, 0
+	}
+
+	first := ""
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+
+		if first == "" || entry.Name() == "info.go" {
+			first = entry.Name()
+		}
+	}
+
+	if first == "" {
+		return dir, 0
+	}
+
+	filename := filepath.Join(dir, first)
+	file, err := parser.ParseFile(v.fset, filename, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return filename, 1
+	}
+
+	return filename, v.fset.Position(file.Package).Line
+}
+
+// sourcePackage is the parsed Go files of a package, indexed by file name.
+type sourcePackage map[string]*ast.File
+
+// parsePackage parses Go files of package name in dir, including tests.
+func (v *validator) parsePackage(dir string, name string) (sourcePackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := make(sourcePackage)
+	var errs scanner.ErrorList
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+
+		filename := filepath.Join(dir, entry.Name())
+		file, err := parser.ParseFile(v.fset, filename, nil, 0)
+		if list, ok := err.(scanner.ErrorList); ok {
+			errs = append(errs, list...)
+			continue
+
+		} else if err != nil {
+			return nil, err
+		}
+
+		if file.Name.Name == name {
+			pkg[filename] = file
+		}
+	}
+
+	return pkg, errs.Err()
+}
+
+// findProblem returns the literal of variable Problem in package.
+func findProblem(pkg sourcePackage) *ast.CompositeLit {
+	for _, file := range pkg {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				for i, name := range value.Names {
+					if name.Name != "Problem" || i >= len(value.Values) {
+						continue
+					}
+
+					if lit, ok := value.Values[i].(*ast.CompositeLit); ok {
+						return lit
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// testedNames returns identifiers used in test files of package.
+func testedNames(pkg sourcePackage) map[string]bool {
+	names := make(map[string]bool)
+	for filename, file := range pkg {
+		if !strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok {
+				names[ident.Name] = true
+			}
+
+			return true
+		})
+	}
+
+	return names
+}
+
+// importsData tells whether package reads its data file with framework.Import.
+func importsData(pkg sourcePackage) bool {
+	found := false
+	for filename, file := range pkg {
+		if strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			if sel, ok := node.(*ast.SelectorExpr); ok && sel.Sel.Name == "Import" {
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == "framework" {
+					found = true
+				}
+			}
+
+			return !found
+		})
+	}
+
+	return found
+}
+
+func (v *validator) checkPackage(name string, registered bool) error {
+	dir := filepath.Join(v.root, ProblemsDir, name)
+	pkg, err := v.parsePackage(dir, name)
+	var errs scanner.ErrorList
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			v.reportAt(e.Pos.Filename, e.Pos.Line, "%s", e.Msg)
+		}
+
+		return nil
+
+	} else if err != nil {
+		return err
+	}
+
+	if len(pkg) == 0 {
+		file, line := v.packagePos(dir)
+		v.reportAt(file, line, "no package %s in directory", name)
+		return nil
+	}
+
+	problem := findProblem(pkg)
+	if problem == nil {
+		file, line := v.packagePos(dir)
+		v.reportAt(file, line, "no variable Problem in package %s", name)
+		return nil
+	}
+
+	if !registered {
+		v.report(problem.Pos(), "package %s is not registered in %s", name, ProblemsListFile)
+	}
+
+	id, _ := strconv.Atoi(problemDirPattern.FindStringSubmatch(name)[1])
+	fields := make(map[string]*ast.KeyValueExpr)
+	for _, elt := range problem.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				fields[key.Name] = kv
+			}
+		}
+	}
+
+	if field, found := fields["Id"]; !found {
+		v.report(problem.Pos(), "Id is not set, expected %d", id)
+
+	} else if lit, ok := field.Value.(*ast.BasicLit); !ok || lit.Value != strconv.Itoa(id) {
+		v.report(field.Pos(), "Id does not match directory %s, expected %d", name, id)
+	}
+
+	v.checkDescription(problem, fields["Description"])
+
+	dataFile := ""
+	if field, found := fields["DataFile"]; found {
+		dataFile, _ = stringLiteral(field.Value)
+		if dataFile != "" && !v.exists(DataPathName, dataFile) {
+			v.report(field.Pos(), "data file %s/%s does not exist", DataPathName, dataFile)
+		}
+	}
+
+	if importsData(pkg) && dataFile != name+".txt" {
+		v.report(problem.Pos(), "package reads %s/%s.txt with framework.Import, but DataFile is not set to it",
+			DataPathName, name)
+	}
+
+	v.checkMethods(problem, fields["Methods"], testedNames(pkg), registered && v.suite)
+	return nil
+}
+
+func (v *validator) checkDescription(problem *ast.CompositeLit, field *ast.KeyValueExpr) {
+	if field == nil {
+		v.report(problem.Pos(), "Description is not set")
+		return
+	}
+
+	lit, ok := field.Value.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+
+	for _, elt := range lit.Elts {
+		if s, ok := stringLiteral(elt); !ok || strings.TrimSpace(s) != "" {
+			return
+		}
+	}
+
+	v.report(field.Pos(), "Description is empty")
+}
+
+// checkMethods checks names of methods, and that they are tested, unless all are tested by the
+// registry suite.
+func (v *validator) checkMethods(problem *ast.CompositeLit, field *ast.KeyValueExpr, tested map[string]bool,
+	suite bool) {
+	if field == nil {
+		v.report(problem.Pos(), "Methods is not set")
+		return
+	}
+
+	lit, ok := field.Value.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+
+		method, _ := stringLiteral(kv.Key)
+		if !methodNamePattern.MatchString(method) {
+			v.report(kv.Pos(), "method name '%s' is not kebab-case", method)
+		}
+
+		if solution, ok := kv.Value.(*ast.Ident); ok && !suite && !tested[solution.Name] {
+			v.report(kv.Pos(), "method '%s' has no test, %s is not used in any test file", method, solution.Name)
+		}
+	}
+}
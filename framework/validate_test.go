@@ -0,0 +1,146 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeValidateTestFile(t *testing.T, root string, name string, content string) {
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateProblems(t *testing.T) {
+	root := t.TempDir()
+	writeValidateTestFile(t, root, ProblemsListFile, `package problems
+
+import (
+	"example.com/m/problems/p0001"
+	"example.com/m/problems/p0002"
+)
+`)
+
+	writeValidateTestFile(t, root, "problems/p0001/info.go", `package p0001
+
+var Problem = framework.Problem{
+	Id:          2,
+	Description: []string{""},
+	DataFile:    "p0001.txt",
+	Methods: map[string]framework.Solution{
+		"naive":     SolveNaive,
+		"With_Hash": SolveHash,
+	},
+}
+`)
+
+	writeValidateTestFile(t, root, "problems/p0001/info_test.go", `package p0001
+
+func TestNaive(t *testing.T) {
+	Problem.Check(t).On(SolveNaive, "naive")
+}
+`)
+
+	writeValidateTestFile(t, root, "problems/p0003/info.go", `package p0003
+
+var Problem = framework.Problem{
+	Id:          3,
+	Description: []string{"Load data."},
+	Methods:     map[string]framework.Solution{},
+}
+
+func Load() { framework.Import() }
+`)
+
+	violations, err := ValidateProblems(root)
+	if err != nil {
+		t.Fatalf("validate failed: %s", err)
+	}
+
+	expected := []string{
+		"framework/problems/list.go:5: imported package example.com/m/problems/p0002 does not exist",
+		"problems/p0001/info.go:4: Id does not match directory p0001, expected 1",
+		"problems/p0001/info.go:5: Description is empty",
+		"problems/p0001/info.go:6: data file data/p0001.txt does not exist",
+		"problems/p0001/info.go:9: method name 'With_Hash' is not kebab-case",
+		"problems/p0001/info.go:9: method 'With_Hash' has no test",
+		"problems/p0003/info.go:3: package p0003 is not registered",
+		"problems/p0003/info.go:3: package reads data/p0003.txt with framework.Import",
+	}
+
+	if len(violations) != len(expected) {
+		t.Fatalf("expected %d violations, got %d: %v", len(expected), len(violations), violations)
+	}
+
+	for i, e := range expected {
+		if !strings.HasPrefix(violations[i].String(), e) {
+			t.Errorf("violation %d: expected '%s', got '%s'", i, e, violations[i])
+		}
+	}
+}
+
+func TestValidateProblemsWithSuite(t *testing.T) {
+	root := t.TempDir()
+	writeValidateTestFile(t, root, ProblemsListFile, `package problems
+
+import (
+	"example.com/m/problems/p0001"
+)
+`)
+
+	writeValidateTestFile(t, root, "framework/problems/problems_test.go", `package problems
+
+func TestProblems(t *testing.T) {
+	framework.RunProblemTests(t, Problems)
+}
+`)
+
+	// Registered methods are tested by the suite, unregistered ones are not.
+	for _, name := range []string{"p0001", "p0002"} {
+		writeValidateTestFile(t, root, "problems/"+name+"/info.go", `package `+name+`
+
+var Problem = framework.Problem{
+	Id:          `+strings.TrimLeft(name[1:], "0")+`,
+	Description: []string{"Solve it."},
+	Methods: map[string]framework.Solution{
+		"naive": SolveNaive,
+	},
+}
+`)
+	}
+
+	writeValidateTestFile(t, root, "problems/p0004/a.go", "package other\n")
+	writeValidateTestFile(t, root, "problems/p0005/a.go", "package p0005\n")
+	writeValidateTestFile(t, root, "problems/p0005/info.go", "// Package p0005 is not done.\n\npackage p0005\n")
+	writeValidateTestFile(t, root, "problems/p0006/notes.txt", "no code yet\n")
+
+	violations, err := ValidateProblems(root)
+	if err != nil {
+		t.Fatalf("validate failed: %s", err)
+	}
+
+	expected := []string{
+		"problems/p0002/info.go:3: package p0002 is not registered",
+		"problems/p0002/info.go:7: method 'naive' has no test",
+		"problems/p0004/a.go:1: no package p0004 in directory",
+		"problems/p0005/info.go:3: no variable Problem in package p0005",
+		"problems/p0006:0: no package p0006 in directory",
+	}
+
+	if len(violations) != len(expected) {
+		t.Fatalf("expected %d violations, got %d: %v", len(expected), len(violations), violations)
+	}
+
+	for i, e := range expected {
+		if !strings.HasPrefix(violations[i].String(), e) {
+			t.Errorf("violation %d: expected '%s', got '%s'", i, e, violations[i])
+		}
+	}
+}
@@ -3,77 +3,227 @@ package framework
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/flily/projeuler.go/framework/connection"
 	"github.com/flily/projeuler.go/framework/message"
 )
 
+// Exit status of worker process.
+const (
+	// WorkerExitOK means worker has shut down after all runs finished.
+	WorkerExitOK = 0
+	// WorkerExitError means worker failed to start or to serve.
+	WorkerExitError = 1
+	// WorkerExitTimeout means a method has timed out. The method can not be stopped and still runs
+	// in background, so worker shuts itself down after sending the timeout result.
+	WorkerExitTimeout = 3
+	// WorkerExitCanceled means running methods were canceled when grace period expired.
+	WorkerExitCanceled = 4
+	// WorkerExitCPULimit means worker has used up its CPU time limit.
+	WorkerExitCPULimit = 5
+)
+
 type Worker struct {
-	Port   int
-	runner *Runner
-	conn   *connection.WorkerConn
-	logger *log.Logger
+	Address     string
+	Concurrency int
+	runner      *Runner
+	conn        *connection.WorkerConn
+	logger      *log.Logger
+
+	ctx          context.Context
+	cancel       context.CancelFunc
+	shutdownOnce sync.Once
+	finished     chan struct{}
+	exitCode     int
+	canceledRuns int32
+
+	// reason tells clients why their runs are rejected or canceled, it is set on shutdown.
+	reasonLock sync.Mutex
+	reason     string
+}
+
+func newWorker(address string, conn *connection.WorkerConn) *Worker {
+	ctx, cancel := context.WithCancel(context.Background())
+	worker := &Worker{
+		Address:     address,
+		Concurrency: 1,
+		conn:        conn,
+		runner:      NewRunner(),
+		logger:      log.New(os.Stderr, "", log.Llongfile|log.Lmicroseconds),
+		ctx:         ctx,
+		cancel:      cancel,
+		finished:    make(chan struct{}),
+	}
+
+	conn.SetShutdownHandler(func(grace time.Duration) {
+		worker.logger.Printf("shutdown requested, grace=%s", grace)
+		worker.Shutdown(WorkerExitOK, grace)
+	})
+
+	conn.SetDropHandler(worker.reject)
+
+	return worker
 }
 
-func NewWorker(host string, port int) (*Worker, error) {
-	conn, err := connection.NewWorkerConn(host, port)
+func NewWorker(address string) (*Worker, error) {
+	conn, err := connection.NewWorkerConn(address)
 	if err != nil {
 		return nil, err
 	}
 
-	worker := &Worker{
-		Port:   port,
-		conn:   conn,
-		runner: NewRunner(),
-		logger: log.New(os.Stderr, "", log.Llongfile|log.Lmicroseconds),
-	}
+	return newWorker(conn.Address(), conn), nil
+}
 
-	return worker, nil
+// NewPipeWorker creates a worker serving the runner process through a pair of pipes.
+func NewPipeWorker(r io.ReadCloser, wr io.WriteCloser) *Worker {
+	return newWorker("stdio", connection.NewPipeWorkerConn(r, wr))
 }
 
 func (w *Worker) Close() {
 	w.conn.Close()
 }
 
+func (w *Worker) SetCodec(codec message.Codec) {
+	w.conn.SetCodec(codec)
+}
+
 func (w *Worker) Import(problems []Problem) {
 	w.runner.Import(problems)
 }
 
+// Serve accepts connections until worker is shut down. A pipe worker is shut down immediately
+// when the pipe is closed, since nobody is waiting for results any more.
 func (w *Worker) Serve() {
 	w.logger.Printf("waiting for connection...")
-	_ = w.conn.RunLoop()
+	err := w.conn.RunLoop()
+	if errors.Is(err, io.EOF) {
+		w.Shutdown(WorkerExitOK, 0)
+	}
 }
 
-func (w *Worker) Process() {
-	for request := range w.conn.RecvRun() {
-		w.DoRun(request)
+// SetConcurrency sets the maximum number of runs in parallel. Runs in parallel disturb the
+// time cost of each other, so only 1 is used by runner.
+func (w *Worker) SetConcurrency(concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
 	}
+
+	w.Concurrency = concurrency
 }
 
-func (w *Worker) DoRun(request *message.MessageRun) {
-	ctx, cancel := NewTimeoutContext(request.MethodTimeout)
+// Shutdown stops accepting runs, waits running methods for grace period and cancels them after
+// that. It returns immediately, and only the first call takes effect.
+func (w *Worker) Shutdown(exitCode int, grace time.Duration) {
+	w.shutdown(exitCode, grace, "worker shutting down")
+}
+
+// shutdown shuts down worker, runs queued but not started are rejected with reason.
+func (w *Worker) shutdown(exitCode int, grace time.Duration, reason string) {
+	w.shutdownOnce.Do(func() {
+		w.exitCode = exitCode
+		w.reasonLock.Lock()
+		w.reason = reason
+		w.reasonLock.Unlock()
+		w.conn.Stop()
+
+		go func() {
+			timer := time.NewTimer(grace)
+			defer timer.Stop()
+
+			select {
+			case <-w.finished:
+			case <-timer.C:
+				if grace > 0 {
+					w.logger.Printf("grace period %s expired, cancel running methods", grace)
+				}
+
+				w.cancel()
+			}
+		}()
+	})
+}
+
+// Process runs requests until worker is shut down, and returns exit status of worker process.
+func (w *Worker) Process() int {
+	wg := sync.WaitGroup{}
+	for i := 0; i < w.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for request := range w.conn.RecvRun() {
+				if w.shutdownReason() != "" {
+					w.reject(request)
+					continue
+				}
+
+				w.DoRun(request)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(w.finished)
+	w.conn.Close()
+
+	if atomic.LoadInt32(&w.canceledRuns) > 0 && w.exitCode == WorkerExitOK {
+		return WorkerExitCanceled
+	}
+
+	return w.exitCode
+}
+
+func (w *Worker) shutdownReason() string {
+	w.reasonLock.Lock()
+	defer w.reasonLock.Unlock()
+	return w.reason
+}
+
+// reject answers a run not started with reason of shutdown.
+func (w *Worker) reject(req *connection.Request) {
+	result := NewResult()
+	result.Message = w.shutdownReason()
+	_ = req.Reply(result.ToMessage())
+}
+
+func (w *Worker) DoRun(req *connection.Request) {
+	request := req.Run
+	ctx, cancel := NewTimeoutContextFrom(w.ctx, request.MethodTimeout)
 	defer cancel()
 
 	w.logger.Printf("run problem %d '%s', timeout=%s", request.Problem, request.Method, request.MethodTimeout)
 	info := NewProblemRunInfo(request.Problem, request.Method)
 	result, err := w.runner.RunProblemWithTimeout(ctx, info)
 	if err == nil {
-		w.conn.SendResult(result.ToMessage())
+		_ = req.Reply(result.ToMessage())
 		return
 	}
 
-	// Process error, send error message to client before panic.
 	w.logger.Printf("run problem %d '%s' failed: %s", request.Problem, request.Method, err)
+	if result == nil {
+		result = NewResult()
+	}
+
 	result.Message = err.Error()
-	if errors.Is(err, context.DeadlineExceeded) {
+	if errors.Is(err, context.Canceled) {
+		atomic.AddInt32(&w.canceledRuns, 1)
+		result.Message = fmt.Sprintf("%s: %s", w.shutdownReason(), err)
+
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		// The timed out method can not be stopped, it would disturb any later run. Shut down
+		// before reply, so the exit status is not overridden by shutdown request from client.
+		// Runs of other sessions are canceled or rejected, telling their clients to retry on
+		// a new worker.
 		w.logger.Printf("timeout: %s", err)
+		w.shutdown(WorkerExitTimeout, 0, fmt.Sprintf("worker restarting, method '%s' of problem %d timed out",
+			request.Method, request.Problem))
 	}
 
-	log.Printf("send error message to client: %+v", result)
-	w.conn.SendResult(result.ToMessage())
-	time.Sleep(100 * time.Millisecond)
-	panic(err)
+	_ = req.Reply(result.ToMessage())
 }
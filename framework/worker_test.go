@@ -0,0 +1,144 @@
+package framework
+
+import (
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestWorker starts a worker serving problem 1 with methods in solutions, and returns it
+// with a channel receiving its exit status.
+func startTestWorker(t *testing.T, solutions map[string]Solution) (*Worker, <-chan int) {
+	worker, err := NewWorker("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("start worker failed: %s", err)
+	}
+
+	worker.logger = log.New(io.Discard, "", 0)
+	worker.Import([]Problem{{Id: 1, NoAnswer: true, Methods: solutions}})
+	exitCode := make(chan int, 1)
+	go worker.Serve()
+	go func() { exitCode <- worker.Process() }()
+	return worker, exitCode
+}
+
+func dialTestWorker(t *testing.T, worker *Worker) *Client {
+	client, err := NewClient(worker.Address)
+	if err != nil {
+		t.Fatalf("connect worker failed: %s", err)
+	}
+
+	return client
+}
+
+func waitExitCode(t *testing.T, exitCode <-chan int, expected int) {
+	t.Helper()
+	select {
+	case code := <-exitCode:
+		if code != expected {
+			t.Errorf("expected worker exit status %d, got %d", expected, code)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatalf("worker does not exit")
+	}
+}
+
+// blockingSolution returns a solution blocking until release is closed, started is sent when it
+// starts running.
+func blockingSolution(started chan<- struct{}, release <-chan struct{}) Solution {
+	return func() int64 {
+		started <- struct{}{}
+		<-release
+		return 1
+	}
+}
+
+func TestWorkerShutdownWaitsGrace(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	worker, exitCode := startTestWorker(t, map[string]Solution{
+		"block": blockingSolution(started, release),
+	})
+
+	client := dialTestWorker(t, worker)
+	defer client.Close()
+	done := make(chan *Result, 1)
+	go func() {
+		result, _ := client.Run(1, "block")
+		done <- result
+	}()
+
+	<-started
+	worker.Shutdown(WorkerExitOK, 5*time.Second)
+	close(release)
+
+	result := <-done
+	if result == nil || len(result.Results) != 1 || result.Results[0].Result != 1 {
+		t.Errorf("run finished in grace period should return result, got %+v", result)
+	}
+
+	waitExitCode(t, exitCode, WorkerExitOK)
+}
+
+func TestWorkerShutdownCancelsAfterGrace(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	defer close(release)
+	worker, exitCode := startTestWorker(t, map[string]Solution{
+		"block": blockingSolution(started, release),
+	})
+
+	client := dialTestWorker(t, worker)
+	defer client.Close()
+	done := make(chan *Result, 1)
+	go func() {
+		result, _ := client.Run(1, "block")
+		done <- result
+	}()
+
+	<-started
+	worker.Shutdown(WorkerExitOK, 20*time.Millisecond)
+	result := <-done
+	if result == nil || !strings.Contains(result.Message, "worker shutting down") {
+		t.Errorf("run canceled after grace period should tell shutdown, got %+v", result)
+	}
+
+	waitExitCode(t, exitCode, WorkerExitCanceled)
+}
+
+func TestWorkerTimeoutRejectsOtherSessions(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	defer close(release)
+	worker, exitCode := startTestWorker(t, map[string]Solution{
+		"block": blockingSolution(started, release),
+		"fast":  func() int64 { return 2 },
+	})
+
+	a := dialTestWorker(t, worker)
+	defer a.Close()
+	timedOut := make(chan *Result, 1)
+	go func() {
+		result, _ := a.RunWithTimeout(1, "block", 0, 100*time.Millisecond)
+		timedOut <- result
+	}()
+
+	// Request of b is queued behind the blocking run, and rejected when it times out.
+	<-started
+	b := dialTestWorker(t, worker)
+	defer b.Close()
+	result, err := b.Run(1, "fast")
+	if err != nil {
+		t.Fatalf("run of other session should be answered, got error %s", err)
+	}
+
+	if len(result.Results) != 0 || !strings.Contains(result.Message, "worker restarting") {
+		t.Errorf("run of other session should be rejected with restarting, got %+v", result)
+	}
+
+	if result := <-timedOut; result == nil || !result.Results[0].IsTimeout {
+		t.Errorf("expected timeout result, got %+v", result)
+	}
+
+	waitExitCode(t, exitCode, WorkerExitTimeout)
+}
@@ -3,6 +3,7 @@ module github.com/flily/projeuler.go
 go 1.18
 
 require (
+    github.com/BurntSushi/toml v1.4.0
     github.com/fatih/color v1.16.0
 )
 
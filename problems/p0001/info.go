@@ -1,6 +1,8 @@
 package p0001
 
 import (
+	"time"
+
 	"github.com/flily/projeuler.go/framework"
 )
 
@@ -13,8 +15,12 @@ var Problem = framework.Problem{
 		``,
 		`Find the sum of all the multiples of 3 or 5 below 1000.`,
 	},
+	Tags:   []string{"arithmetic"},
 	Answer: 233168,
 	Methods: map[string]framework.Solution{
 		"naive": SolveNaive,
 	},
+	Budgets: map[string]time.Duration{
+		"naive": 100 * time.Millisecond,
+	},
 }
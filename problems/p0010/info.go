@@ -1,6 +1,8 @@
 package p0010
 
 import (
+	"time"
+
 	"github.com/flily/projeuler.go/framework"
 )
 
@@ -12,8 +14,12 @@ var Problem = framework.Problem{
 		``,
 		`Find the sum of all the primes below two million.`,
 	},
+	Tags:   []string{"primes"},
 	Answer: 142913828922,
 	Methods: map[string]framework.Solution{
 		"naive": SolveNaive,
 	},
+	Budgets: map[string]time.Duration{
+		"naive": time.Second,
+	},
 }
@@ -1,6 +1,8 @@
 package p0014
 
 import (
+	"time"
+
 	"github.com/flily/projeuler.go/framework"
 )
 
@@ -25,10 +27,16 @@ var Problem = framework.Problem{
 		``,
 		`NOTE: Once the chain starts the terms are allowed to go above one million.`,
 	},
+	Tags:   []string{"sequence"},
 	Answer: 837799,
 	Methods: map[string]framework.Solution{
 		"naive":           SolveNaive,
 		"with-cache-map":  SolveCacheMap,
 		"with-cache-list": SolveCacheList,
 	},
+	Budgets: map[string]time.Duration{
+		"naive":           2 * time.Second,
+		"with-cache-map":  2 * time.Second,
+		"with-cache-list": 200 * time.Millisecond,
+	},
 }
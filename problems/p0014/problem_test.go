@@ -0,0 +1,11 @@
+package p0014
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheList(t *testing.T) {
+	Problem.Check(t).Within(200*time.Millisecond).MaxAllocBytes(64<<20).BestOf(3).
+		On(SolveCacheList, "with-cache-list")
+}
@@ -1,6 +1,8 @@
 package p0022
 
 import (
+	"time"
+
 	"github.com/flily/projeuler.go/framework"
 )
 
@@ -18,10 +20,15 @@ var Problem = framework.Problem{
 		`obtain a score of 938 × 53 = 49714.`,
 		`What is the total of all the name scores in the file?`,
 	},
-	Answer: 871198282,
+	Tags:     []string{"strings"},
+	Answer:   871198282,
+	DataFile: "p0022.txt",
 	Methods: map[string]framework.Solution{
 		"naive": SolveNaive,
 	},
+	Budgets: map[string]time.Duration{
+		"naive": 100 * time.Millisecond,
+	},
 }
 
 func Load() []string {
@@ -1,6 +1,8 @@
 package p0023
 
 import (
+	"time"
+
 	"github.com/flily/projeuler.go/framework"
 )
 
@@ -25,10 +27,17 @@ var Problem = framework.Problem{
 		`Find the sum of all the positive integers which cannot be written as the sum of two`,
 		`abundant numbers.`,
 	},
+	Tags:   []string{"divisors"},
 	Answer: 4179871,
 	Methods: map[string]framework.Solution{
 		"naive":                 SolveNaive,
 		"with-factor-sum-cache": SolveWithFactorSumCache,
 		"with-substraction":     SolveWithSubstraction,
 	},
+	Budgets: map[string]time.Duration{
+		"naive":                 20 * time.Second,
+		"with-factor-sum-cache": 2 * time.Second,
+		"with-substraction":     500 * time.Millisecond,
+	},
+	Slow: []string{"naive"},
 }
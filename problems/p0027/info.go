@@ -1,6 +1,8 @@
 package p0027
 
 import (
+	"time"
+
 	"github.com/flily/projeuler.go/framework"
 )
 
@@ -29,9 +31,14 @@ var Problem = framework.Problem{
 		`Find the product of the coefficients, a and b, for the quadratic expression that produces`,
 		`the maximum number of primes for consecutive values of n, starting with n = 0.`,
 	},
+	Tags:   []string{"primes"},
 	Answer: -59231,
 	Methods: map[string]framework.Solution{
 		"naive": SolveNaive,
 		"cache": SolveCache,
 	},
+	Budgets: map[string]time.Duration{
+		"naive": 500 * time.Millisecond,
+		"cache": 500 * time.Millisecond,
+	},
 }
@@ -1,6 +1,8 @@
 package p0039
 
 import (
+	"time"
+
 	"github.com/flily/projeuler.go/framework"
 )
 
@@ -13,9 +15,14 @@ var Problem = framework.Problem{
 		``,
 		`For which value of p ≤ 1000, is the number of solutions maximised?`,
 	},
+	Tags:   []string{"geometry"},
 	Answer: 840,
 	Methods: map[string]framework.Solution{
 		"naive":   SolveNaive,
 		"ordered": SolveOrdered,
 	},
+	Budgets: map[string]time.Duration{
+		"naive":   time.Second,
+		"ordered": 200 * time.Millisecond,
+	},
 }
@@ -2,8 +2,13 @@ package p0039
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNaive(t *testing.T) {
 	Problem.Check(t).On(SolveNaive, "naive")
 }
+
+func TestOrdered(t *testing.T) {
+	Problem.Check(t).Within(200*time.Millisecond).BestOf(3).On(SolveOrdered, "ordered")
+}